@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/cmd/common"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/config"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/exit"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+func NewAnalyzeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "analyze [flags]",
+		Short: "Trigger a vulnerability analysis of a project and wait for its completion",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := loadServerConfig()
+			if err != nil {
+				logger.Default().Error("Error validating server config", "error", err)
+				return err
+			}
+			ProjectUUID := viper.GetString(common.VProjectUUID)
+			ProjectName := viper.GetString(common.VProjectName)
+			ProjectVersion := viper.GetString(common.VProjectVersion)
+			AnalysisTimeout := viper.GetDuration(common.VAnalysisTimeout)
+
+			ctx := context.TODO()
+			client, err := newClient(server)
+			if err != nil {
+				logger.Default().Error("Error connecting to dependencytrack", "error", err)
+				return err
+			}
+			project, err := resolveProject(ctx, client, ProjectUUID, ProjectName, ProjectVersion)
+			if err != nil {
+				logger.Default().Error("Error resolving project", "error", err)
+				return err
+			}
+			err = analyzeProject(ctx, client, project, time.Now(), AnalysisTimeout)
+			if err != nil {
+				logger.Default().Error("Error analyzing project", "error", err)
+				return err
+			}
+			return nil
+		},
+		Example: `
+# Re-analyze a project, e.g. after a vulnerability database update, and wait for fresh metrics:
+trivy dependencytrack analyze --project-name my-project --project-version main --analysis-timeout 10m
+`,
+	}
+
+	addServerFlags(cmd)
+	addProjectFlags(cmd)
+
+	cmd.Flags().Duration(common.VAnalysisTimeout, common.VAnalysisTimeoutDefault, common.VAnalysisTimeoutUsage)
+	err := viper.BindPFlag(common.VAnalysisTimeout, cmd.Flags().Lookup(common.VAnalysisTimeoutLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	return cmd
+}
+
+// addWaitForAnalysisFlags registers the flags waiting for the analysis after an upload.
+func addWaitForAnalysisFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool(common.VWaitForAnalysis, common.VWaitForAnalysisDefault, common.VWaitForAnalysisUsage)
+	err := viper.BindPFlag(common.VWaitForAnalysis, cmd.Flags().Lookup(common.VWaitForAnalysisLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().Duration(common.VAnalysisTimeout, common.VAnalysisTimeoutDefault, common.VAnalysisTimeoutUsage)
+	err = viper.BindPFlag(common.VAnalysisTimeout, cmd.Flags().Lookup(common.VAnalysisTimeoutLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+}
+
+// analyzeProject triggers a vulnerability analysis, waits for its event, then waits until the project metrics were
+// computed after since, so that findings and metrics read afterwards are complete. timeout bounds the whole wait.
+func analyzeProject(ctx context.Context, client *dtrack.Client, project dtrack.Project, since time.Time, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	token, err := client.Finding.AnalyzeProject(ctx, project.UUID)
+	if err != nil {
+		return fmt.Errorf("failed to trigger analysis: %w", err)
+	}
+	logger.Default().Info("Vulnerability analysis triggered", "project", project.Name, "version", project.Version)
+	err = waitForEvent(client, dtrack.EventToken(token), time.Until(deadline))
+	if err != nil {
+		return fmt.Errorf("failed to wait for analysis: %w", err)
+	}
+
+	err = client.Metrics.RefreshProjectMetrics(ctx, project.UUID)
+	if err != nil {
+		return fmt.Errorf("failed to refresh metrics: %w", err)
+	}
+	err = waitForMetrics(ctx, client, project, since, time.Until(deadline))
+	if err != nil {
+		return fmt.Errorf("failed to wait for metrics: %w", err)
+	}
+	logger.Default().Info("Vulnerability analysis completed", "project", project.Name, "version", project.Version)
+	return nil
+}
+
+// metricsPollInterval is the delay between two reads of the project metrics.
+var metricsPollInterval = 2 * time.Second
+
+// waitForMetrics polls the project metrics until their last occurrence is after since or the timeout is exceeded.
+func waitForMetrics(ctx context.Context, client *dtrack.Client, project dtrack.Project, since time.Time, timeout time.Duration) error {
+	ticker := time.NewTicker(metricsPollInterval)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+
+	for {
+		m, err := client.Metrics.LatestProjectMetrics(ctx, project.UUID)
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+		if err == nil && time.UnixMilli(int64(m.LastOccurrence)).After(since) {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			return exit.Timeout(fmt.Errorf("metrics not computed after %s", timeout))
+		}
+	}
+}
+
+// findingsReaders names the enabled steps that read the findings of a project version after its upload.
+func findingsReaders(steps map[string]bool) []string {
+	var readers []string
+	for step, enabled := range steps {
+		if enabled {
+			readers = append(readers, step)
+		}
+	}
+	slices.Sort(readers)
+	return readers
+}
+
+// waitForAnalysisAfterUpload analyzes an uploaded project when wait-for-analysis is set, or when readers read its
+// findings afterwards: DependencyTrack analyzes a new version asynchronously, so they would see no finding yet.
+func waitForAnalysisAfterUpload(server config.Server, projectName string, projectVersion string, uploadedAt time.Time, readers []string) error {
+	if !viper.GetBool(common.VWaitForAnalysis) {
+		if len(readers) == 0 {
+			return nil
+		}
+		logger.Default().Info("Waiting for the vulnerability analysis, the findings are read after the upload", "by", readers)
+	}
+	ctx := context.TODO()
+	client, err := newClient(server)
+	if err != nil {
+		return err
+	}
+	project, err := resolveProject(ctx, client, "", projectName, projectVersion)
+	if err != nil {
+		return err
+	}
+	return analyzeProject(ctx, client, project, uploadedAt, viper.GetDuration(common.VAnalysisTimeout))
+}
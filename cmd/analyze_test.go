@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/exit"
+
+	dtrack "github.com/DependencyTrack/client-go"
+	"github.com/google/uuid"
+)
+
+// fakeAnalysis serves the analysis, event and metrics endpoints of a project. processing tells whether the analysis
+// event is still being processed, metrics answers each read of the latest metrics with a status and a last occurrence.
+type fakeAnalysis struct {
+	processing func() bool
+	metrics    func(read int) (int, time.Time)
+
+	mu           sync.Mutex
+	metricsReads int
+	refreshed    bool
+}
+
+func (f *fakeAnalysis) start(t *testing.T) (*dtrack.Client, dtrack.Project) {
+	project := dtrack.Project{UUID: uuid.New(), Name: "web", Version: "main"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/version", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(dtrack.About{Version: "4.11.0"})
+	})
+	mux.HandleFunc("POST /api/v1/finding/project/"+project.UUID.String()+"/analyze", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": uuid.NewString()})
+	})
+	mux.HandleFunc("GET /api/v1/event/token/{token}", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]bool{"processing": f.processing()})
+	})
+	mux.HandleFunc("GET /api/v1/metrics/project/"+project.UUID.String()+"/refresh", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.refreshed = true
+		f.mu.Unlock()
+	})
+	mux.HandleFunc("GET /api/v1/metrics/project/"+project.UUID.String()+"/current", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.metricsReads++
+		status, last := f.metrics(f.metricsReads)
+		f.mu.Unlock()
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(dtrack.ProjectMetrics{LastOccurrence: int(last.UnixMilli())})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := dtrack.NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, project
+}
+
+// fastPolling shortens the poll intervals for the duration of a test.
+func fastPolling(t *testing.T) {
+	event, metrics := eventPollInterval, metricsPollInterval
+	eventPollInterval, metricsPollInterval = 5*time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() { eventPollInterval, metricsPollInterval = event, metrics })
+}
+
+func TestWaitForMetrics(t *testing.T) {
+	fastPolling(t)
+	since := time.Now()
+	stale, fresh := since.Add(-time.Hour), since.Add(time.Second)
+
+	tests := []struct {
+		name      string
+		metrics   func(read int) (int, time.Time)
+		wantReads int
+		wantKind  exit.Kind
+		wantErr   bool
+	}{
+		{
+			name:      "computed after since",
+			metrics:   func(int) (int, time.Time) { return http.StatusOK, fresh },
+			wantReads: 1,
+		},
+		{
+			name: "polled until computed after since",
+			metrics: func(read int) (int, time.Time) {
+				if read < 3 {
+					return http.StatusOK, stale
+				}
+				return http.StatusOK, fresh
+			},
+			wantReads: 3,
+		},
+		{
+			name: "no metrics yet",
+			metrics: func(read int) (int, time.Time) {
+				if read == 1 {
+					return http.StatusNotFound, time.Time{}
+				}
+				return http.StatusOK, fresh
+			},
+			wantReads: 2,
+		},
+		{
+			name:     "never computed",
+			metrics:  func(int) (int, time.Time) { return http.StatusOK, stale },
+			wantErr:  true,
+			wantKind: exit.KindTimeout,
+		},
+		{
+			name:      "server error",
+			metrics:   func(int) (int, time.Time) { return http.StatusInternalServerError, time.Time{} },
+			wantReads: 1,
+			wantErr:   true,
+			wantKind:  exit.KindServer,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeAnalysis{metrics: tt.metrics}
+			client, project := fake.start(t)
+
+			err := waitForMetrics(context.Background(), client, project, since, 100*time.Millisecond)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("waitForMetrics() error = %v", err)
+				}
+			} else if err == nil || exit.KindOf(err) != tt.wantKind {
+				t.Fatalf("waitForMetrics() error = %v, want a %s error", err, tt.wantKind)
+			}
+			if tt.wantReads > 0 && fake.metricsReads != tt.wantReads {
+				t.Errorf("waitForMetrics() read the metrics %d times, want %d", fake.metricsReads, tt.wantReads)
+			}
+		})
+	}
+}
+
+func TestAnalyzeProject(t *testing.T) {
+	fastPolling(t)
+
+	t.Run("waits for the event then the metrics", func(t *testing.T) {
+		since := time.Now()
+		polls := 0
+		fake := &fakeAnalysis{
+			processing: func() bool {
+				polls++
+				return polls < 3
+			},
+			metrics: func(read int) (int, time.Time) {
+				if read == 1 {
+					return http.StatusNotFound, time.Time{}
+				}
+				return http.StatusOK, time.Now()
+			},
+		}
+		client, project := fake.start(t)
+
+		if err := analyzeProject(context.Background(), client, project, since, time.Second); err != nil {
+			t.Fatalf("analyzeProject() error = %v", err)
+		}
+		if polls != 3 || !fake.refreshed || fake.metricsReads != 2 {
+			t.Errorf("analyzeProject() polled the event %d times, refreshed %v and read the metrics %d times, want 3, true and 2",
+				polls, fake.refreshed, fake.metricsReads)
+		}
+	})
+
+	t.Run("event and metrics share the deadline", func(t *testing.T) {
+		const timeout = 300 * time.Millisecond
+		start := time.Now()
+		fake := &fakeAnalysis{
+			processing: func() bool { return time.Since(start) < 200*time.Millisecond },
+			metrics:    func(int) (int, time.Time) { return http.StatusOK, start.Add(-time.Hour) },
+		}
+		client, project := fake.start(t)
+
+		err := analyzeProject(context.Background(), client, project, start, timeout)
+		if exit.KindOf(err) != exit.KindTimeout {
+			t.Fatalf("analyzeProject() error = %v, want a timeout", err)
+		}
+		if elapsed := time.Since(start); elapsed > timeout+150*time.Millisecond {
+			t.Errorf("analyzeProject() returned after %s, want about %s", elapsed, timeout)
+		}
+		if fake.metricsReads == 0 {
+			t.Error("analyzeProject() timed out before reading the metrics")
+		}
+	})
+
+	t.Run("event not processed in time", func(t *testing.T) {
+		fake := &fakeAnalysis{processing: func() bool { return true }}
+		client, project := fake.start(t)
+
+		err := analyzeProject(context.Background(), client, project, time.Now(), 50*time.Millisecond)
+		if exit.KindOf(err) != exit.KindTimeout {
+			t.Fatalf("analyzeProject() error = %v, want a timeout", err)
+		}
+		if fake.refreshed {
+			t.Error("analyzeProject() refreshed the metrics of an unfinished analysis")
+		}
+	})
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/ci"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
+)
+
+// detectCIContext reads the build context of the named CI provider, or of the detected one for "auto".
+func detectCIContext(name string) (ci.Context, error) {
+	provider, err := ci.Lookup(name, os.Getenv)
+	if err != nil {
+		return ci.Context{}, err
+	}
+	c := provider.Context(os.Getenv)
+	logger.Default().Info("CI context", "provider", c.Provider, "refType", c.RefType, "project", c.ProjectName,
+		"version", c.Version, "baseline", c.Baseline(), "commit", c.CommitSHA, "buildUrl", c.BuildURL)
+	return c, nil
+}
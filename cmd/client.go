@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/cmd/common"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/auth"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/config"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/exit"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/httpclient"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	dtrack "github.com/DependencyTrack/client-go"
+	"github.com/google/uuid"
+)
+
+// newClient builds a DependencyTrack client on the HTTP client shared by the commands.
+func newClient(server config.Server) (*dtrack.Client, error) {
+	httpClient, err := sharedHTTPClient(server)
+	if err != nil {
+		return nil, err
+	}
+	client, err := dtrack.NewClient(server.URLAPI, dtrack.WithHttpClient(httpClient), dtrack.WithUserAgent(httpclient.UserAgent(pluginVersion)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dependencytrack client: %w", err)
+	}
+	return client, nil
+}
+
+var httpClientOnce struct {
+	sync.Once
+	client *http.Client
+	err    error
+}
+
+// sharedHTTPClient builds, on the first call, the HTTP client used for every request to DependencyTrack: TLS, proxy,
+// headers and timeout of the server settings, authenticated with its api key, bearer token or login. The settings of
+// the first call apply for the whole run, and a login happens only once.
+func sharedHTTPClient(server config.Server) (*http.Client, error) {
+	httpClientOnce.Do(func() {
+		if server.InsecureSkipVerify {
+			logger.Default().Warn("TLS certificate verification is DISABLED by insecure-skip-verify: the DependencyTrack server is not authenticated and credentials or SBOMs can be intercepted, use ca-cert instead", "url", server.URLAPI)
+		}
+		client, err := httpclient.New(server.TLS, server.HTTP, httpclient.UserAgent(pluginVersion))
+		if err != nil {
+			httpClientOnce.err = err
+			return
+		}
+		transport, err := clientAuth(server, client)
+		if err != nil {
+			httpClientOnce.err = err
+			return
+		}
+		httpClientOnce.client = &http.Client{Timeout: client.Timeout, Transport: transport}
+	})
+	return httpClientOnce.client, httpClientOnce.err
+}
+
+// clientAuth sets the api key header, or a bearer token renewed when DependencyTrack rejects it, on top of the
+// transport of client. A login goes through client.
+func clientAuth(server config.Server, client *http.Client) (http.RoundTripper, error) {
+	var source auth.TokenSource
+	switch {
+	case server.APIKey != "":
+		return httpclient.WithHeader(client.Transport, http.Header{"X-Api-Key": {server.APIKey}}), nil
+	case server.BearerTokenFile != "":
+		source = auth.File(server.BearerTokenFile)
+	case server.BearerToken != "":
+		source = auth.Static(server.BearerToken)
+	case server.Username != "":
+		source = auth.Login(server.URLAPI, server.Username, server.Password, dtrack.WithHttpClient(client), dtrack.WithUserAgent(httpclient.UserAgent(pluginVersion)))
+	default:
+		return nil, fmt.Errorf("no dependencytrack auth method set")
+	}
+
+	transport, err := auth.NewTransport(context.TODO(), client.Transport, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate to dependencytrack: %w", err)
+	}
+	return transport, nil
+}
+
+// addServerFlags registers the flags needed to reach DependencyTrack.
+func addServerFlags(cmd *cobra.Command) {
+	cmd.Flags().String(common.VUrlApi, common.VUrlApiDefault, common.VUrlApiUsage)
+	err := viper.BindPFlag(common.VUrlApi, cmd.Flags().Lookup(common.VUrlApiLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VApiKey, common.VApiKeyDefault, common.VApiKeyUsage)
+	err = viper.BindPFlag(common.VApiKey, cmd.Flags().Lookup(common.VApiKeyLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VApiKeyFile, common.VApiKeyFileDefault, common.VApiKeyFileUsage)
+	err = viper.BindPFlag(common.VApiKeyFile, cmd.Flags().Lookup(common.VApiKeyFileLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VApiKeyCommand, common.VApiKeyCommandDefault, common.VApiKeyCommandUsage)
+	err = viper.BindPFlag(common.VApiKeyCommand, cmd.Flags().Lookup(common.VApiKeyCommandLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VBearerToken, common.VBearerTokenDefault, common.VBearerTokenUsage)
+	err = viper.BindPFlag(common.VBearerToken, cmd.Flags().Lookup(common.VBearerTokenLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VBearerTokenFile, common.VBearerTokenFileDefault, common.VBearerTokenFileUsage)
+	err = viper.BindPFlag(common.VBearerTokenFile, cmd.Flags().Lookup(common.VBearerTokenFileLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VUsername, common.VUsernameDefault, common.VUsernameUsage)
+	err = viper.BindPFlag(common.VUsername, cmd.Flags().Lookup(common.VUsernameLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VPassword, common.VPasswordDefault, common.VPasswordUsage)
+	err = viper.BindPFlag(common.VPassword, cmd.Flags().Lookup(common.VPasswordLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VCACert, common.VCACertDefault, common.VCACertUsage)
+	err = viper.BindPFlag(common.VCACert, cmd.Flags().Lookup(common.VCACertLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VClientCert, common.VClientCertDefault, common.VClientCertUsage)
+	err = viper.BindPFlag(common.VClientCert, cmd.Flags().Lookup(common.VClientCertLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VClientKey, common.VClientKeyDefault, common.VClientKeyUsage)
+	err = viper.BindPFlag(common.VClientKey, cmd.Flags().Lookup(common.VClientKeyLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VTLSMinVersion, common.VTLSMinVersionDefault, common.VTLSMinVersionUsage)
+	err = viper.BindPFlag(common.VTLSMinVersion, cmd.Flags().Lookup(common.VTLSMinVersionLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().Bool(common.VInsecureSkipVerify, common.VInsecureSkipVerifyDefault, common.VInsecureSkipVerifyUsage)
+	err = viper.BindPFlag(common.VInsecureSkipVerify, cmd.Flags().Lookup(common.VInsecureSkipVerifyLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VProxy, common.VProxyDefault, common.VProxyUsage)
+	err = viper.BindPFlag(common.VProxy, cmd.Flags().Lookup(common.VProxyLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VNoProxy, common.VNoProxyDefault, common.VNoProxyUsage)
+	err = viper.BindPFlag(common.VNoProxy, cmd.Flags().Lookup(common.VNoProxyLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().StringArray(common.VHeader, nil, common.VHeaderUsage)
+	err = viper.BindPFlag(common.VHeader, cmd.Flags().Lookup(common.VHeaderLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().StringArray(common.VSecretHeader, nil, common.VSecretHeaderUsage)
+	err = viper.BindPFlag(common.VSecretHeader, cmd.Flags().Lookup(common.VSecretHeaderLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().Duration(common.VTimeout, common.VTimeoutDefault, common.VTimeoutUsage)
+	err = viper.BindPFlag(common.VTimeout, cmd.Flags().Lookup(common.VTimeoutLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+}
+
+// addProjectFlags registers the flags selecting a project by UUID or by name and version.
+func addProjectFlags(cmd *cobra.Command) {
+	cmd.Flags().String(common.VProjectUUID, common.VProjectUUIDDefault, common.VProjectUUIDUsage)
+	err := viper.BindPFlag(common.VProjectUUID, cmd.Flags().Lookup(common.VProjectUUIDLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VProjectName, common.VProjectNameDefault, common.VProjectNameUsage)
+	err = viper.BindPFlag(common.VProjectName, cmd.Flags().Lookup(common.VProjectNameLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VProjectVersion, common.VProjectVersionDefault, common.VProjectVersionUsage)
+	err = viper.BindPFlag(common.VProjectVersion, cmd.Flags().Lookup(common.VProjectVersionLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+}
+
+// resolveProject finds a project by UUID, or by name and version when no UUID is given.
+func resolveProject(ctx context.Context, client *dtrack.Client, projectUUID string, projectName string, projectVersion string) (dtrack.Project, error) {
+	if projectUUID != "" {
+		id, err := uuid.Parse(projectUUID)
+		if err != nil {
+			return dtrack.Project{}, exit.Config(fmt.Errorf("invalid project uuid %q: %w", projectUUID, err))
+		}
+		project, err := client.Project.Get(ctx, id)
+		if err != nil {
+			return dtrack.Project{}, fmt.Errorf("failed to get project %s: %w", id, err)
+		}
+		return project, nil
+	}
+
+	if projectName == "" || projectVersion == "" {
+		return dtrack.Project{}, exit.Config(fmt.Errorf("a project uuid or a project name and version are required"))
+	}
+	project, err := client.Project.Lookup(ctx, projectName, projectVersion)
+	if err != nil {
+		return dtrack.Project{}, fmt.Errorf("failed to lookup project %s@%s: %w", projectName, projectVersion, err)
+	}
+	return project, nil
+}
+
+// isNotFound reports whether err is a DependencyTrack 404 answer.
+func isNotFound(err error) bool {
+	var apiErr *dtrack.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == 404
+}
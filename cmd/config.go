@@ -0,0 +1,387 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/cmd/common"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/ci"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/config"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/exit"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/findings"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/metrics"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/naming"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/project"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/triage"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// secretConfigKeys are redacted wherever config values are printed.
+var secretConfigKeys = []string{common.VApiKey, common.VBearerToken, common.VPassword, common.VProxy, common.VHeader, common.VGitlabToken}
+
+func NewConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config [command]",
+		Short: "Write, inspect and validate the plugin configuration",
+		// An invalid logging config must not prevent inspecting it, fall back to the default logger.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			err := preRun(cmd, args)
+			if err != nil {
+				logger.Default().Warn("Error applying the logging config, using defaults", "error", err)
+			}
+			commandRunning = true
+			return nil
+		},
+	}
+
+	cmd.AddCommand(NewConfigInitCommand())
+	cmd.AddCommand(NewConfigShowCommand())
+	cmd.AddCommand(NewConfigValidateCommand())
+
+	return cmd
+}
+
+func NewConfigInitCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init [file]",
+		Short: "Write a commented config file template",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keys := configRegistry(cmd.Root()).Sorted()
+			if len(args) > 0 && args[0] == "-" {
+				return config.WriteTemplate(os.Stdout, common.VEnvPrefix, keys)
+			}
+
+			path := ""
+			if len(args) > 0 {
+				path = args[0]
+			} else {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return err
+				}
+				path = filepath.Join(home, common.VDefaultConfigName+".yaml")
+			}
+			// The file may hold API keys once filled in.
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+			if errors.Is(err, os.ErrExist) {
+				err = fmt.Errorf("%s already exists, remove it or choose another file", path)
+			}
+			if err != nil {
+				logger.Default().Error("Error creating config file", "error", err)
+				return err
+			}
+			defer f.Close()
+			err = config.WriteTemplate(f, common.VEnvPrefix, keys)
+			if err != nil {
+				logger.Default().Error("Error writing config file", "error", err)
+				return err
+			}
+			logger.Default().Info("Config file written", "cfgFile", path)
+			return nil
+		},
+		Example: `
+# Write $HOME/.trivy_plugin_dependencytrack.yaml, the config file read by default:
+trivy dependencytrack config init
+
+# Print the template:
+trivy dependencytrack config init -
+`,
+	}
+
+	return cmd
+}
+
+func NewConfigShowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show [command [flags]]",
+		Short: "Print the effective configuration and where each value comes from",
+		Long: `Print the effective configuration and where each value comes from: flag, env, file, file (profile) or default.
+Secrets are redacted. Given a command and its flags, only the keys of that command are printed, as it would see them.
+Global flags such as --config and --profile go before the command.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			keys := configRegistry(root).Sorted()
+			flags := cmd.Flags()
+			if len(args) > 0 {
+				target, rest, err := root.Find(args)
+				if err == nil && target == root {
+					err = fmt.Errorf("unknown command %q", args[0])
+				}
+				if err != nil {
+					logger.Default().Error("Error finding command", "error", err)
+					return err
+				}
+				err = target.ParseFlags(rest)
+				if err != nil {
+					logger.Default().Error("Error parsing command flags", "error", err)
+					return err
+				}
+				err = viper.BindPFlags(target.Flags())
+				if err != nil {
+					return err
+				}
+				flags = target.Flags()
+				keys = slices.DeleteFunc(keys, func(k config.Key) bool { return k.Flag == "" || flags.Lookup(k.Flag) == nil })
+			}
+			return writeEffectiveConfig(os.Stdout, keys, flags)
+		},
+		Example: `
+# Print the configuration read from flags, environment variables and the config file:
+trivy dependencytrack config show
+
+# Print the configuration an upload would use with the production profile:
+trivy dependencytrack config show --profile production upload --project-name my-project
+`,
+	}
+	// Stop at the command name, the remaining flags being those of the command.
+	cmd.Flags().SetInterspersed(false)
+
+	return cmd
+}
+
+func NewConfigValidateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Report unknown keys, wrong types and invalid values of the config file and environment",
+		Long: `Report unknown keys, wrong types and invalid values of the config file, its profiles included, and of the
+TRIVY_PLUGIN_DEPENDENCYTRACK_* environment variables. Exits with an error when a problem is found.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			validation := configValidation(configRegistry(cmd.Root()))
+			var problems []config.Problem
+
+			path := viper.ConfigFileUsed()
+			if path == "" {
+				logger.Default().Info("No config file found, only checking environment variables")
+			} else {
+				content, err := os.ReadFile(path)
+				if err != nil {
+					logger.Default().Error("Error reading config file", "error", err)
+					return err
+				}
+				values := map[string]any{}
+				err = yaml.Unmarshal(content, &values)
+				if err != nil {
+					logger.Default().Error("Error decoding config file", "error", err)
+					return err
+				}
+				problems = append(problems, validation.File(path, values)...)
+			}
+			problems = append(problems, validation.Env(common.VEnvPrefix, os.Environ())...)
+
+			// The auth method may be given on the command line, only conflicting or incomplete settings are reported.
+			var server config.Server
+			err := viper.Unmarshal(&server)
+			if err == nil && server.HasAuth() {
+				err = server.ValidateAuth()
+				if err != nil {
+					problems = append(problems, config.Problem{Source: "merged config", Key: "auth", Message: err.Error()})
+				}
+			}
+
+			err = writeConfigProblems(os.Stdout, problems)
+			if err != nil {
+				return err
+			}
+			if len(problems) > 0 {
+				err := fmt.Errorf("%d configuration problems found", len(problems))
+				logger.Default().Error("Error validating configuration", "error", err)
+				return exit.Config(err)
+			}
+			logger.Default().Info("Configuration is valid")
+			return nil
+		},
+		Example: `
+# Check the default config file and the environment:
+trivy dependencytrack config validate
+
+# Check another config file:
+trivy dependencytrack config validate --config ./dependencytrack.yaml
+`,
+	}
+
+	return cmd
+}
+
+// writeConfigProblems prints one problem per line.
+func writeConfigProblems(w io.Writer, problems []config.Problem) error {
+	for _, p := range problems {
+		if _, err := fmt.Fprintln(w, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configRegistry collects the config keys of every command flag, plus the config file only keys.
+func configRegistry(root *cobra.Command) config.Registry {
+	r := config.Registry{}
+	r.Add(root.PersistentFlags())
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		r.Add(c.Flags())
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+	}
+	walk(root)
+
+	r[common.VProfiles] = config.Key{Name: common.VProfiles, Type: config.TypeMap, Description: "Config profiles selected with --profile"}
+	r[common.VTriageRules] = config.Key{Name: common.VTriageRules, Type: config.TypeList, Description: "Auto-triage rules"}
+	return r
+}
+
+// configValidation checks the values of the keys with a fixed set of values or a format.
+func configValidation(registry config.Registry) config.Validation {
+	oneOf := func(values ...string) func(string) error {
+		return func(s string) error {
+			if !slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, s) }) {
+				return fmt.Errorf("invalid value %q, expected one of %v", s, values)
+			}
+			return nil
+		}
+	}
+	isUUID := func(s string) error {
+		_, err := uuid.Parse(s)
+		return err
+	}
+	isRegexp := func(s string) error {
+		_, err := regexp.Compile(s)
+		return err
+	}
+	isState := func(s string) error {
+		_, err := triage.ParseState(s)
+		return err
+	}
+	isJustification := func(s string) error {
+		_, err := triage.ParseJustification(s)
+		return err
+	}
+
+	return config.Validation{
+		Registry: registry,
+		Validators: map[string]func(string) error{
+			common.VUrlApi: config.ValidateURL,
+			common.VApiKey: config.ValidateAPIKey,
+			common.VLogLevel: func(s string) error {
+				_, err := logger.ParseLevel(s)
+				return err
+			},
+			common.VLogFormat:     oneOf(string(logger.FormatConsole), string(logger.FormatJSON), string(logger.FormatDev), string(logger.FormatNone)),
+			common.VCI:            oneOf(append([]string{ci.Auto}, ci.Names()...)...),
+			common.VTLSMinVersion: oneOf(slices.Sorted(maps.Keys(config.TLSVersions))...),
+			common.VFailOnSeverity: func(s string) error {
+				_, err := findings.ParseSeverity(s)
+				return err
+			},
+			common.VAnalysisState:            isState,
+			common.VTrivyIgnoreState:         isState,
+			common.VAnalysisJustification:    isJustification,
+			common.VTrivyIgnoreJustification: isJustification,
+			common.VAnalysisResponse: func(s string) error {
+				_, err := triage.ParseResponse(s)
+				return err
+			},
+			common.VStatus:     oneOf(project.StatusAll, project.StatusActive, project.StatusInactive),
+			common.VClassifier: oneOf(project.Classifiers...),
+			common.VVexFormat:  oneOf("openvex", "cyclonedx"),
+			common.VOutput:     oneOf("table", "json", "yaml", "markdown", metrics.FormatSparkline),
+			common.VSince: func(s string) error {
+				_, err := metrics.ParseSince(s, time.Now())
+				return err
+			},
+			common.VProjectUUID:            isUUID,
+			common.VBaselineUUID:           isUUID,
+			common.VParentUUID:             isUUID,
+			common.VGitlabBranchInclude:    isRegexp,
+			common.VGitlabBranchExclude:    isRegexp,
+			common.VGitlabTagInclude:       isRegexp,
+			common.VGitlabTagExclude:       isRegexp,
+			common.VProjectNameTemplate:    naming.Check,
+			common.VProjectVersionTemplate: naming.Check,
+		},
+		Structured: map[string]func(any) error{
+			common.VTriageRules: func(v any) error {
+				content, err := yaml.Marshal(v)
+				if err != nil {
+					return err
+				}
+				var rules []triage.Rule
+				err = yaml.Unmarshal(content, &rules)
+				if err != nil {
+					return fmt.Errorf("expected a list of rules: %w", err)
+				}
+				_, err = triage.NewRuleSet(rules)
+				return err
+			},
+		},
+		ExtraEnv: []string{
+			config.EnvName(common.VEnvPrefix, common.VConfig),
+			config.EnvName(common.VEnvPrefix, common.VGitlabToken) + "_FILE",
+			config.EnvName(common.VEnvPrefix, common.VPassword) + "_FILE",
+		},
+	}
+}
+
+// writeEffectiveConfig prints the value of each key and its source, the flag source only counting the given flags.
+func writeEffectiveConfig(w io.Writer, keys []config.Key, flags *pflag.FlagSet) error {
+	profileName := viper.GetString(common.VProfile)
+	profile, _ := viper.GetStringMap(common.VProfiles)[profileName].(map[string]any)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, k := range keys {
+		source := "default"
+		_, inEnv := os.LookupEnv(config.EnvName(common.VEnvPrefix, k.Name))
+		_, inProfile := profile[k.Name]
+		switch {
+		case k.Flag != "" && flags.Lookup(k.Flag) != nil && flags.Lookup(k.Flag).Changed:
+			source = "flag"
+		case inEnv:
+			source = "env"
+		case inProfile:
+			source = fmt.Sprintf("file (profile %s)", profileName)
+		case viper.InConfig(k.Name):
+			source = "file"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", k.Name, configValue(k), source)
+	}
+	return tw.Flush()
+}
+
+func configValue(k config.Key) string {
+	switch k.Name {
+	case common.VProfiles:
+		return fmt.Sprint(slices.Sorted(maps.Keys(viper.GetStringMap(common.VProfiles))))
+	case common.VTriageRules:
+		rules, _ := viper.Get(common.VTriageRules).([]any)
+		return fmt.Sprintf("%d rules", len(rules))
+	}
+	value := fmt.Sprint(viper.Get(k.Name))
+	if viper.Get(k.Name) == nil {
+		value = ""
+	}
+	if slices.Contains(secretConfigKeys, k.Name) && value != "" {
+		return "<redacted>"
+	}
+	if value == "" {
+		return `""`
+	}
+	return value
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/cmd/common"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/config"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/doctor"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/httpclient"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+func NewDoctorCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor [flags]",
+		Short: "Check the connection, version and permissions to DependencyTrack",
+		Long: `Check, step by step, what an upload needs: the config, the DNS resolution, TCP connection and TLS handshake to
+url-api (or to the proxy), the version endpoint, the credentials and the permissions of their team or user.
+Each check passes, warns or fails, the checks depending on a failed one being skipped. Exits with an error when a check
+fails.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				logger.Default().Error("Error loading config", "error", err)
+				return err
+			}
+
+			results := runDoctor(context.TODO(), cfg)
+			err = doctor.Write(os.Stdout, results)
+			if err != nil {
+				logger.Default().Error("Error writing checks", "error", err)
+				return err
+			}
+			if doctor.Failed(results) {
+				err = fmt.Errorf("dependencytrack checks failed")
+				logger.Default().Error("Error checking dependencytrack", "error", err)
+				return err
+			}
+			return nil
+		},
+		Example: `
+# Check why uploads fail in CI, with the same flags and environment variables as the upload:
+trivy dependencytrack doctor --url-api https://dependencytrack.example.com
+
+# Check a config profile, without project auto-creation:
+trivy dependencytrack doctor --profile production --auto-create=false
+
+# Also require the permission to read the findings, as the gate of the upload does:
+trivy dependencytrack doctor --fail-on-severity high
+`,
+	}
+
+	addServerFlags(cmd)
+
+	cmd.Flags().Bool(common.VAutoCreate, common.VAutoCreateDefault, common.VAutoCreateUsage)
+	err := viper.BindPFlag(common.VAutoCreate, cmd.Flags().Lookup(common.VAutoCreateLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	addUploadDiffFlags(cmd)
+
+	return cmd
+}
+
+// uploadReadsFindings reports whether the upload reads the findings back, for a gate or a findings diff, a merge
+// request note or a GitHub step summary, as configured for the upload commands.
+func uploadReadsFindings() bool {
+	_, diffEnabled := uploadDiffOptions()
+	mrNote := viper.GetBool(common.VGitlabMRNote) && os.Getenv("CI_MERGE_REQUEST_IID") != ""
+	stepSummary := viper.GetBool(common.VGithubStepSummary) && os.Getenv("GITHUB_STEP_SUMMARY") != ""
+	return diffEnabled || mrNote || stepSummary
+}
+
+// runDoctor runs the checks in order, skipping those that depend on a failed one.
+func runDoctor(ctx context.Context, cfg config.Config) []doctor.Result {
+	server := cfg.Server
+	var results []doctor.Result
+	add := func(r doctor.Result) bool {
+		results = append(results, r)
+		return r.Status != doctor.Fail
+	}
+	skip := func(reason string, checks ...string) []doctor.Result {
+		for _, check := range checks {
+			results = append(results, doctor.Result{Check: check, Status: doctor.Skip, Detail: reason})
+		}
+		return results
+	}
+
+	if err := server.Validate(); err != nil {
+		add(doctor.Result{Check: "config", Status: doctor.Fail, Detail: strings.ReplaceAll(err.Error(), "\n", "; ")})
+	} else {
+		add(doctor.Result{Check: "config", Status: doctor.Pass, Detail: fmt.Sprintf("%s with %s", server.URLAPI, authMethod(server))})
+	}
+	target, err := url.Parse(server.URLAPI)
+	if err != nil || config.ValidateURL(server.URLAPI) != nil {
+		return skip("invalid url-api", "dns", "tcp", "tls", "version", "auth")
+	}
+	timeout := server.Timeout
+	if timeout == 0 {
+		timeout = httpclient.DefaultTimeout
+	}
+
+	// Through a proxy, the connection is opened to the proxy and the TLS handshake with DependencyTrack is tunneled.
+	dial := target
+	proxyURL, err := httpclient.Proxy(server.HTTP)(&http.Request{URL: target})
+	if err != nil {
+		add(doctor.Result{Check: "proxy", Status: doctor.Fail, Detail: err.Error()})
+		return skip("invalid proxy", "dns", "tcp", "tls", "version", "auth")
+	}
+	if proxyURL != nil {
+		add(doctor.Result{Check: "proxy", Status: doctor.Pass, Detail: "requests go through " + proxyURL.Redacted()})
+		dial = proxyURL
+	}
+
+	if !add(doctor.DNS(ctx, dial.Hostname())) {
+		return skip("no address", "tcp", "tls", "version", "auth")
+	}
+	if !add(doctor.TCP(ctx, net.JoinHostPort(dial.Hostname(), port(dial)), timeout)) {
+		return skip("no connection", "tls", "version", "auth")
+	}
+	switch {
+	case target.Scheme != "https":
+		add(doctor.Result{Check: "tls", Status: doctor.Warn, Detail: "url-api is plain http, the credentials are sent unencrypted"})
+	case proxyURL != nil:
+		add(doctor.Result{Check: "tls", Status: doctor.Skip, Detail: "tunneled through the proxy, checked by the version request"})
+	default:
+		tlsConfig, err := httpclient.NewTLSConfig(server.TLS)
+		if err != nil {
+			add(doctor.Result{Check: "tls", Status: doctor.Fail, Detail: err.Error()})
+			return skip("no TLS connection", "version", "auth")
+		}
+		if !add(doctor.TLS(ctx, net.JoinHostPort(target.Hostname(), port(target)), target.Hostname(), tlsConfig, timeout)) {
+			return skip("no TLS connection", "version", "auth")
+		}
+	}
+
+	plain, err := httpclient.New(server.TLS, server.HTTP, httpclient.UserAgent(pluginVersion))
+	if err != nil {
+		add(doctor.Result{Check: "version", Status: doctor.Fail, Detail: err.Error()})
+		return skip("no version", "auth")
+	}
+	client, err := dtrack.NewClient(server.URLAPI, dtrack.WithHttpClient(plain), dtrack.WithUserAgent(httpclient.UserAgent(pluginVersion)))
+	if err != nil {
+		add(doctor.Result{Check: "version", Status: doctor.Fail, Detail: err.Error()})
+		return skip("no version", "auth")
+	}
+	if !add(doctor.Version(ctx, client)) {
+		return skip("no version", "auth")
+	}
+
+	if err := server.ValidateAuth(); err != nil {
+		return skip("no valid auth method", "auth")
+	}
+	result, permissions := authCheck(ctx, server)
+	if !add(result) {
+		return skip("not authenticated", "permissions")
+	}
+	add(doctor.Result{Check: "permissions", Status: doctor.Pass, Detail: strings.Join(doctor.PermissionNames(permissions), ", ")})
+	for _, r := range doctor.Permissions(permissions, doctor.UploadRequirements(cfg.AutoCreate, uploadReadsFindings())) {
+		add(r)
+	}
+	return results
+}
+
+// authCheck authenticates to DependencyTrack and returns the permissions of the API key team, or of the user.
+func authCheck(ctx context.Context, server config.Server) (doctor.Result, []dtrack.Permission) {
+	if server.APIKey != "" {
+		httpClient, err := sharedHTTPClient(server)
+		if err != nil {
+			return doctor.Result{Check: "auth", Status: doctor.Fail, Detail: err.Error()}, nil
+		}
+		team, err := doctor.TeamSelf(ctx, httpClient, server.URLAPI)
+		if err != nil {
+			return doctor.Result{Check: "auth", Status: doctor.Fail, Detail: err.Error()}, nil
+		}
+		return doctor.Result{Check: "auth", Status: doctor.Pass, Detail: fmt.Sprintf("API key of team %s", team.Name)}, team.Permissions
+	}
+
+	client, err := newClient(server)
+	if err != nil {
+		return doctor.Result{Check: "auth", Status: doctor.Fail, Detail: err.Error()}, nil
+	}
+	user, err := client.User.GetSelf(ctx)
+	if err != nil {
+		return doctor.Result{Check: "auth", Status: doctor.Fail, Detail: err.Error()}, nil
+	}
+	return doctor.Result{Check: "auth", Status: doctor.Pass, Detail: fmt.Sprintf("%s as user %s", authMethod(server), user.Username)}, user.Permissions
+}
+
+// authMethod names the auth method of the server settings.
+func authMethod(server config.Server) string {
+	switch {
+	case server.APIKey != "":
+		return "API key"
+	case server.BearerToken != "" || server.BearerTokenFile != "":
+		return "bearer token"
+	default:
+		return "login"
+	}
+}
+
+// port returns the port of u, defaulting to the one of its scheme.
+func port(u *url.URL) string {
+	if p := u.Port(); p != "" {
+		return p
+	}
+	if u.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
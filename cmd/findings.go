@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/cmd/common"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/config"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/exit"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/findings"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/report"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+// diffOptions selects the baseline of a findings diff and the gate applied to its result.
+type diffOptions struct {
+	BaselineUUID    string
+	BaselineVersion string
+	Output          string
+	Gate            findings.Gate
+}
+
+func NewFindingsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "findings [command]",
+		Short: "Inspect the findings of a DependencyTrack project",
+	}
+
+	cmd.AddCommand(NewFindingsDiffCommand())
+
+	return cmd
+}
+
+func NewFindingsDiffCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff [flags]",
+		Short: "Compare the findings of a project version with a baseline version",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := loadServerConfig()
+			if err != nil {
+				logger.Default().Error("Error validating server config", "error", err)
+				return err
+			}
+			ProjectUUID := viper.GetString(common.VProjectUUID)
+			ProjectName := viper.GetString(common.VProjectName)
+			ProjectVersion := viper.GetString(common.VProjectVersion)
+			opts := diffOptions{
+				BaselineUUID:    viper.GetString(common.VBaselineUUID),
+				BaselineVersion: viper.GetString(common.VBaselineVersion),
+				Output:          viper.GetString(common.VOutput),
+				Gate: findings.Gate{
+					Severity:       viper.GetString(common.VFailOnSeverity),
+					IntroducedOnly: viper.GetBool(common.VGateIntroducedOnly),
+				},
+			}
+			if opts.BaselineUUID == "" && opts.BaselineVersion == "" {
+				err := fmt.Errorf("baseline-version or baseline-uuid is required")
+				logger.Default().Error("Error validating fields", "error", err)
+				return exit.Config(err)
+			}
+
+			ctx := context.TODO()
+			client, err := newClient(server)
+			if err != nil {
+				logger.Default().Error("Error connecting to dependencytrack", "error", err)
+				return err
+			}
+			project, err := resolveProject(ctx, client, ProjectUUID, ProjectName, ProjectVersion)
+			if err != nil {
+				logger.Default().Error("Error resolving project", "error", err)
+				return err
+			}
+			summary, err := summarize(ctx, client, project, opts)
+			if err != nil {
+				logger.Default().Error("Error comparing findings", "error", err)
+				return err
+			}
+			return checkSummary(summary, opts)
+		},
+		Example: `
+# Compare the findings of a feature branch with the ones of main:
+trivy dependencytrack findings diff --project-name my-project --project-version feature-x --baseline-version main
+
+# Fail when the feature branch introduces high or critical vulnerabilities:
+trivy dependencytrack findings diff --project-name my-project --project-version feature-x --baseline-version main \
+  --fail-on-severity high --gate-introduced-only
+`,
+	}
+
+	addServerFlags(cmd)
+	addDiffFlags(cmd, true)
+
+	cmd.Flags().StringP(common.VOutputLong, common.VOutputShort, common.VOutputDefault, common.VOutputUsage)
+	err := viper.BindPFlag(common.VOutput, cmd.Flags().Lookup(common.VOutputLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	return cmd
+}
+
+// addDiffFlags registers the baseline and gate flags shared by the diff and upload commands. The project name and
+// version flags are already registered by the upload commands, so they are only added on demand.
+func addDiffFlags(cmd *cobra.Command, withProject bool) {
+	if withProject {
+		addProjectFlags(cmd)
+	}
+
+	cmd.Flags().String(common.VBaselineVersion, common.VBaselineVersionDefault, common.VBaselineVersionUsage)
+	err := viper.BindPFlag(common.VBaselineVersion, cmd.Flags().Lookup(common.VBaselineVersionLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VBaselineUUID, common.VBaselineUUIDDefault, common.VBaselineUUIDUsage)
+	err = viper.BindPFlag(common.VBaselineUUID, cmd.Flags().Lookup(common.VBaselineUUIDLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VFailOnSeverity, common.VFailOnSeverityDefault, common.VFailOnSeverityUsage)
+	err = viper.BindPFlag(common.VFailOnSeverity, cmd.Flags().Lookup(common.VFailOnSeverityLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().Bool(common.VGateIntroducedOnly, common.VGateIntroducedOnlyDefault, common.VGateIntroducedOnlyUsage)
+	err = viper.BindPFlag(common.VGateIntroducedOnly, cmd.Flags().Lookup(common.VGateIntroducedOnlyLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+}
+
+// addUploadDiffFlags registers the post-upload findings diff flags.
+func addUploadDiffFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool(common.VFindingsDiff, common.VFindingsDiffDefault, common.VFindingsDiffUsage)
+	err := viper.BindPFlag(common.VFindingsDiff, cmd.Flags().Lookup(common.VFindingsDiffLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	addDiffFlags(cmd, false)
+}
+
+// uploadDiffOptions reads the post-upload findings diff settings. It returns false when no diff was requested.
+func uploadDiffOptions() (diffOptions, bool) {
+	opts := diffOptions{
+		BaselineUUID:    viper.GetString(common.VBaselineUUID),
+		BaselineVersion: viper.GetString(common.VBaselineVersion),
+		Output:          report.FormatTable,
+		Gate: findings.Gate{
+			Severity:       viper.GetString(common.VFailOnSeverity),
+			IntroducedOnly: viper.GetBool(common.VGateIntroducedOnly),
+		},
+	}
+	enabled := viper.GetBool(common.VFindingsDiff) || opts.BaselineUUID != "" || opts.BaselineVersion != "" || opts.Gate.Enabled()
+	return opts, enabled
+}
+
+// summarizeUpload compares the findings of a freshly uploaded project version with its baseline.
+func summarizeUpload(server config.Server, projectName string, projectVersion string, opts diffOptions) (report.Summary, error) {
+	ctx := context.TODO()
+	client, err := newClient(server)
+	if err != nil {
+		return report.Summary{}, err
+	}
+	project, err := resolveProject(ctx, client, "", projectName, projectVersion)
+	if err != nil {
+		return report.Summary{}, err
+	}
+	return summarize(ctx, client, project, opts)
+}
+
+// summarize compares the findings of a project version with the ones of its baseline. A missing baseline is not an
+// error: every finding is then reported as introduced.
+func summarize(ctx context.Context, client *dtrack.Client, project dtrack.Project, opts diffOptions) (report.Summary, error) {
+	summary := report.Summary{ProjectName: project.Name, ProjectVersion: project.Version}
+
+	current, err := findings.Fetch(ctx, client, project.UUID)
+	if err != nil {
+		return summary, fmt.Errorf("failed to fetch findings: %w", err)
+	}
+	violations, err := findings.FetchViolations(ctx, client, project.UUID)
+	if err != nil {
+		return summary, fmt.Errorf("failed to fetch policy violations: %w", err)
+	}
+
+	var baseline []dtrack.Finding
+	var baselineViolations []dtrack.PolicyViolation
+	if opts.BaselineUUID != "" || opts.BaselineVersion != "" {
+		baselineProject, err := resolveProject(ctx, client, opts.BaselineUUID, project.Name, opts.BaselineVersion)
+		switch {
+		case isNotFound(err):
+			logger.Default().Warn("Baseline project version not found, every finding is reported as introduced",
+				"uuid", opts.BaselineUUID, "version", opts.BaselineVersion)
+		case err != nil:
+			return summary, err
+		case baselineProject.UUID == project.UUID:
+			summary.Baseline = baselineProject.Version
+			baseline, baselineViolations = current, violations
+		default:
+			summary.Baseline = baselineProject.Version
+			baseline, err = findings.Fetch(ctx, client, baselineProject.UUID)
+			if err != nil {
+				return summary, fmt.Errorf("failed to fetch baseline findings: %w", err)
+			}
+			baselineViolations, err = findings.FetchViolations(ctx, client, baselineProject.UUID)
+			if err != nil {
+				return summary, fmt.Errorf("failed to fetch baseline policy violations: %w", err)
+			}
+		}
+	}
+
+	summary.Findings = findings.Compare(current, baseline)
+	summary.Violations = findings.CompareViolations(violations, baselineViolations)
+	logger.Default().Debug("Findings compared", "project", project.Name, "version", project.Version, "baseline", summary.Baseline,
+		"introduced", len(summary.Findings.Introduced), "fixed", len(summary.Findings.Fixed), "unchanged", len(summary.Findings.Unchanged))
+	return summary, nil
+}
+
+// checkSummary prints the summary and evaluates the gate against it.
+func checkSummary(summary report.Summary, opts diffOptions) error {
+	err := report.Write(os.Stdout, opts.Output, summary)
+	if err != nil {
+		logger.Default().Error("Error writing findings", "error", err)
+		return err
+	}
+	err = opts.Gate.Evaluate(summary.Findings)
+	if err != nil {
+		logger.Default().Error("Vulnerability gate failed", "error", err)
+		return exit.Gate(err)
+	}
+	return nil
+}
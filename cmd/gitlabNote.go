@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/exit"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/gitlab"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/report"
+)
+
+// gitlabNoteMarker is a hidden Markdown comment identifying the note of a project, so that it is updated on the next
+// pipeline instead of posting a new one.
+const gitlabNoteMarker = "<!-- trivy-plugin-dependencytrack project=%q -->"
+
+func postGitlabMRNote(gitlabToken string, summary report.Summary) error {
+	apiURL := os.Getenv("CI_API_V4_URL")
+	projectID := os.Getenv("CI_PROJECT_ID")
+	mrIID := os.Getenv("CI_MERGE_REQUEST_IID")
+	if apiURL == "" || projectID == "" || mrIID == "" {
+		return exit.Config(fmt.Errorf("CI_API_V4_URL, CI_PROJECT_ID and CI_MERGE_REQUEST_IID are required to post a merge request note"))
+	}
+	if gitlabToken == "" {
+		return exit.Config(fmt.Errorf("gitlab-token is required to post a merge request note"))
+	}
+
+	note, created, err := gitlab.NewClient(apiURL, projectID, gitlabToken).
+		UpsertMergeRequestNote(context.TODO(), mrIID, fmt.Sprintf(gitlabNoteMarker, summary.ProjectName), report.Markdown(summary))
+	if err != nil {
+		return err
+	}
+	if created {
+		logger.Default().Info("GitLab merge request note created", "mr", mrIID, "note", note.ID)
+	} else {
+		logger.Default().Info("GitLab merge request note updated", "mr", mrIID, "note", note.ID)
+	}
+	return nil
+}
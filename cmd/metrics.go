@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/cmd/common"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/exit"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/metrics"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewMetricsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "metrics [command]",
+		Short: "Show DependencyTrack project metrics",
+	}
+
+	cmd.AddCommand(NewMetricsShowCommand())
+
+	return cmd
+}
+
+func NewMetricsShowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show [flags]",
+		Short: "Show the current metrics of a project, or their history",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := loadServerConfig()
+			if err != nil {
+				logger.Default().Error("Error validating server config", "error", err)
+				return err
+			}
+			ProjectUUID := viper.GetString(common.VProjectUUID)
+			ProjectName := viper.GetString(common.VProjectName)
+			ProjectVersion := viper.GetString(common.VProjectVersion)
+			Since := viper.GetString(common.VSince)
+			Output := viper.GetString(common.VOutput)
+
+			var since time.Time
+			if Since != "" {
+				var err error
+				since, err = metrics.ParseSince(Since, time.Now())
+				if err != nil {
+					logger.Default().Error("Error validating fields", "error", err)
+					return exit.Config(err)
+				}
+			} else if Output == metrics.FormatSparkline {
+				err := fmt.Errorf("the %s output needs --%s", metrics.FormatSparkline, common.VSinceLong)
+				logger.Default().Error("Error validating fields", "error", err)
+				return exit.Config(err)
+			}
+
+			ctx := context.TODO()
+			client, err := newClient(server)
+			if err != nil {
+				logger.Default().Error("Error connecting to dependencytrack", "error", err)
+				return err
+			}
+			project, err := resolveProject(ctx, client, ProjectUUID, ProjectName, ProjectVersion)
+			if err != nil {
+				logger.Default().Error("Error resolving project", "error", err)
+				return err
+			}
+
+			if Since == "" {
+				m, err := client.Metrics.LatestProjectMetrics(ctx, project.UUID)
+				if err != nil {
+					logger.Default().Error("Error fetching project metrics", "error", err)
+					return err
+				}
+				return metrics.Write(os.Stdout, Output, metrics.Current{
+					ProjectUUID:    project.UUID.String(),
+					ProjectName:    project.Name,
+					ProjectVersion: project.Version,
+					Metrics:        m,
+				})
+			}
+
+			history, err := client.Metrics.ProjectMetricsSince(ctx, project.UUID, since)
+			if err != nil {
+				logger.Default().Error("Error fetching project metrics history", "error", err)
+				return err
+			}
+			return metrics.WriteHistory(os.Stdout, Output, metrics.History{
+				ProjectUUID:    project.UUID.String(),
+				ProjectName:    project.Name,
+				ProjectVersion: project.Version,
+				Since:          since,
+				Metrics:        history,
+			})
+		},
+		Example: `
+# Show the current severity counts, risk score, audit progress and policy violations:
+trivy dependencytrack metrics show --project-name my-project --project-version main
+
+# Show the trend of the last 30 days as sparklines:
+trivy dependencytrack metrics show --project-name my-project --project-version main --since 30d -o sparkline
+
+# Export the history since a date for a dashboard:
+trivy dependencytrack metrics show --project-uuid 5e0f5fd4-7ba0-4ac5-8fb8-a8e1ac7a6f7e --since 2025-01-01 -o json
+`,
+	}
+
+	addServerFlags(cmd)
+	addProjectFlags(cmd)
+
+	cmd.Flags().String(common.VSince, common.VSinceDefault, common.VSinceUsage)
+	err := viper.BindPFlag(common.VSince, cmd.Flags().Lookup(common.VSinceLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().StringP(common.VOutputLong, common.VOutputShort, common.VOutputDefault, common.VMetricsOutputUsage)
+	err = viper.BindPFlag(common.VOutput, cmd.Flags().Lookup(common.VOutputLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	return cmd
+}
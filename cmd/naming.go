@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/cmd/common"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/ci"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/naming"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func addProjectNamingFlags(cmd *cobra.Command) {
+	cmd.Flags().String(common.VProjectNameTemplate, common.VProjectNameTemplateDefault, common.VProjectNameTemplateUsage)
+	err := viper.BindPFlag(common.VProjectNameTemplate, cmd.Flags().Lookup(common.VProjectNameTemplateLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VProjectVersionTemplate, common.VProjectVersionTemplateDefault, common.VProjectVersionTemplateUsage)
+	err = viper.BindPFlag(common.VProjectVersionTemplate, cmd.Flags().Lookup(common.VProjectVersionTemplateLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().Bool(common.VProjectFromBOM, common.VProjectFromBOMDefault, common.VProjectFromBOMUsage)
+	err = viper.BindPFlag(common.VProjectFromBOM, cmd.Flags().Lookup(common.VProjectFromBOMLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VProjectFromBOMLatest, common.VProjectFromBOMLatestDefault, common.VProjectFromBOMLatestUsage)
+	err = viper.BindPFlag(common.VProjectFromBOMLatest, cmd.Flags().Lookup(common.VProjectFromBOMLatestLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+}
+
+// resolveProjectNaming fills the project name and version not set explicitly, from the templates first, then from the
+// sbom metadata with --project-from-bom. Values still empty are left to the CI fallbacks of the caller.
+func resolveProjectNaming(projectName, projectVersion string, c ci.Context, bomFile string) (string, string, error) {
+	projectName, projectVersion, err := renderProjectTemplates(projectName, projectVersion, c, bomFile)
+	if err != nil {
+		return "", "", err
+	}
+	return projectFromBOM(projectName, projectVersion, bomFile)
+}
+
+// renderProjectTemplates renders the project name and version templates for the values not set explicitly, c
+// providing the CI fields of the template data. Values without template are returned unchanged so that the CI
+// fallbacks still apply.
+func renderProjectTemplates(projectName, projectVersion string, c ci.Context, bomFile string) (string, string, error) {
+	nameTemplate := viper.GetString(common.VProjectNameTemplate)
+	versionTemplate := viper.GetString(common.VProjectVersionTemplate)
+	if projectName != "" {
+		nameTemplate = ""
+	}
+	if projectVersion != "" {
+		versionTemplate = ""
+	}
+	if nameTemplate == "" && versionTemplate == "" {
+		return projectName, projectVersion, nil
+	}
+
+	var bom naming.Component
+	if bomFile != "" {
+		var err error
+		bom, err = naming.ReadBOMComponent(bomFile)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	data := naming.NewData(c, bom, naming.ReadGit("."), os.Environ())
+
+	if nameTemplate != "" {
+		name, err := naming.Render(common.VProjectNameTemplate, nameTemplate, data)
+		if err != nil {
+			return "", "", err
+		}
+		projectName = name
+	}
+	if versionTemplate != "" {
+		version, err := naming.Render(common.VProjectVersionTemplate, versionTemplate, data)
+		if err != nil {
+			return "", "", err
+		}
+		projectVersion = version
+	}
+	logger.Default().Info("Project rendered from templates", "project", projectName, "version", projectVersion)
+	return projectName, projectVersion, nil
+}
+
+// projectFromBOM fills the missing project name and version from the sbom metadata.component when --project-from-bom
+// is set.
+func projectFromBOM(projectName, projectVersion string, bomFile string) (string, string, error) {
+	if !viper.GetBool(common.VProjectFromBOM) || bomFile == "" || (projectName != "" && projectVersion != "") {
+		return projectName, projectVersion, nil
+	}
+
+	component, err := naming.ReadBOMComponent(bomFile)
+	if err != nil {
+		return "", "", err
+	}
+	name, version := naming.FromBOM(component, viper.GetString(common.VProjectFromBOMLatest))
+	if projectName == "" {
+		projectName = name
+	}
+	if projectVersion == "" {
+		projectVersion = version
+	}
+	for _, value := range []string{projectName, projectVersion} {
+		if value == "" {
+			continue
+		}
+		if err := naming.Validate(value); err != nil {
+			return "", "", fmt.Errorf("sbom metadata gave %q: %w", value, err)
+		}
+	}
+	logger.Default().Info("Project resolved from sbom metadata", "component", component.Name, "type", component.Type,
+		"project", projectName, "version", projectVersion)
+	return projectName, projectVersion, nil
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/cmd/common"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/exit"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/project"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/google/uuid"
+)
+
+func NewProjectCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "project [command]",
+		Short: "Find, inspect and manage DependencyTrack projects",
+	}
+
+	cmd.AddCommand(NewProjectListCommand())
+	cmd.AddCommand(NewProjectGetCommand())
+	cmd.AddCommand(NewProjectUpdateCommand())
+	cmd.AddCommand(NewProjectActivateCommand())
+	cmd.AddCommand(NewProjectDeactivateCommand())
+	cmd.AddCommand(NewProjectDeleteCommand())
+
+	return cmd
+}
+
+func NewProjectListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [flags]",
+		Short: "List DependencyTrack projects",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := loadServerConfig()
+			if err != nil {
+				logger.Default().Error("Error validating server config", "error", err)
+				return err
+			}
+			Output := viper.GetString(common.VOutput)
+			filter := project.Filter{
+				Name:   viper.GetString(common.VNameFilter),
+				Tag:    viper.GetString(common.VTag),
+				Status: viper.GetString(common.VStatus),
+			}
+			if parent := viper.GetString(common.VParentUUID); parent != "" {
+				id, err := uuid.Parse(parent)
+				if err != nil {
+					err = fmt.Errorf("invalid parent uuid %q: %w", parent, err)
+					logger.Default().Error("Error validating fields", "error", err)
+					return exit.Config(err)
+				}
+				filter.Parent = id
+			}
+			err = filter.Validate()
+			if err != nil {
+				logger.Default().Error("Error validating fields", "error", err)
+				return exit.Config(err)
+			}
+
+			ctx := context.TODO()
+			client, err := newClient(server)
+			if err != nil {
+				logger.Default().Error("Error connecting to dependencytrack", "error", err)
+				return err
+			}
+			projects, err := project.List(ctx, client, filter)
+			if err != nil {
+				logger.Default().Error("Error listing projects", "error", err)
+				return err
+			}
+			return project.Write(os.Stdout, Output, projects)
+		},
+		Example: `
+# List every version of the projects whose name contains "api":
+trivy dependencytrack project list --name api
+
+# List the active projects tagged team-a as YAML:
+trivy dependencytrack project list --tag team-a --status active -o yaml
+
+# List the children of a project:
+trivy dependencytrack project list --parent-uuid 5e0f5fd4-7ba0-4ac5-8fb8-a8e1ac7a6f7e
+`,
+	}
+
+	addServerFlags(cmd)
+	addProjectOutputFlag(cmd)
+
+	cmd.Flags().String(common.VNameFilter, common.VNameFilterDefault, common.VNameFilterUsage)
+	err := viper.BindPFlag(common.VNameFilter, cmd.Flags().Lookup(common.VNameFilterLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VTag, common.VTagDefault, common.VTagUsage)
+	err = viper.BindPFlag(common.VTag, cmd.Flags().Lookup(common.VTagLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VStatus, common.VStatusDefault, common.VStatusUsage)
+	err = viper.BindPFlag(common.VStatus, cmd.Flags().Lookup(common.VStatusLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VParentUUID, common.VParentUUIDDefault, common.VParentUUIDUsage)
+	err = viper.BindPFlag(common.VParentUUID, cmd.Flags().Lookup(common.VParentUUIDLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	return cmd
+}
+
+func NewProjectGetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get [flags]",
+		Short: "Show a DependencyTrack project",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := loadServerConfig()
+			if err != nil {
+				logger.Default().Error("Error validating server config", "error", err)
+				return err
+			}
+			ProjectUUID := viper.GetString(common.VProjectUUID)
+			ProjectName := viper.GetString(common.VProjectName)
+			ProjectVersion := viper.GetString(common.VProjectVersion)
+			Output := viper.GetString(common.VOutput)
+
+			ctx := context.TODO()
+			client, err := newClient(server)
+			if err != nil {
+				logger.Default().Error("Error connecting to dependencytrack", "error", err)
+				return err
+			}
+			p, err := resolveProject(ctx, client, ProjectUUID, ProjectName, ProjectVersion)
+			if err != nil {
+				logger.Default().Error("Error resolving project", "error", err)
+				return err
+			}
+			return project.WriteProject(os.Stdout, Output, p)
+		},
+		Example: `
+# Show a project version, e.g. to find its UUID:
+trivy dependencytrack project get --project-name my-project --project-version main
+
+# Show a project as JSON:
+trivy dependencytrack project get --project-uuid 5e0f5fd4-7ba0-4ac5-8fb8-a8e1ac7a6f7e -o json
+`,
+	}
+
+	addServerFlags(cmd)
+	addProjectFlags(cmd)
+	addProjectOutputFlag(cmd)
+
+	return cmd
+}
+
+// addProjectOutputFlag registers the output format flag of the project commands.
+func addProjectOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP(common.VOutputLong, common.VOutputShort, common.VOutputDefault, common.VProjectOutputUsage)
+	err := viper.BindPFlag(common.VOutput, cmd.Flags().Lookup(common.VOutputLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+}
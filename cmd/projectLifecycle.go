@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/cmd/common"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/exit"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/project"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+func NewProjectDeleteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete [flags]",
+		Short: "Delete DependencyTrack projects",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProjectLifecycle(cmd, "delete", true, func(ctx context.Context, client *dtrack.Client, p dtrack.Project) error {
+				err := client.Project.Delete(ctx, p.UUID)
+				if err != nil {
+					return err
+				}
+				logger.Default().Info("Project deleted", "uuid", p.UUID, "name", p.Name, "version", p.Version)
+				return nil
+			})
+		},
+		Example: `
+# Delete a project version:
+trivy dependencytrack project delete --project-name my-project --project-version feature-x
+
+# Delete every feature branch version without asking:
+trivy dependencytrack project delete --project-name my-project --project-version 'feature-*' --yes
+`,
+	}
+
+	addProjectLifecycleFlags(cmd)
+
+	return cmd
+}
+
+func NewProjectActivateCommand() *cobra.Command {
+	return newProjectActiveCommand(true)
+}
+
+func NewProjectDeactivateCommand() *cobra.Command {
+	return newProjectActiveCommand(false)
+}
+
+func newProjectActiveCommand(active bool) *cobra.Command {
+	action, done := "activate", "Project activated"
+	if !active {
+		action, done = "deactivate", "Project deactivated"
+	}
+	cmd := &cobra.Command{
+		Use:   action + " [flags]",
+		Short: strings.ToUpper(action[:1]) + action[1:] + " DependencyTrack projects",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProjectLifecycle(cmd, action, false, func(ctx context.Context, client *dtrack.Client, p dtrack.Project) error {
+				changed, err := project.SetActive(ctx, client, p, active)
+				if err != nil {
+					return err
+				}
+				if !changed {
+					logger.Default().Info("Project unchanged", "uuid", p.UUID, "name", p.Name, "version", p.Version, "active", active)
+					return nil
+				}
+				logger.Default().Info(done, "uuid", p.UUID, "name", p.Name, "version", p.Version)
+				return nil
+			})
+		},
+		Example: fmt.Sprintf(`
+# %[1]s a project version:
+trivy dependencytrack project %[2]s --project-uuid 5e0f5fd4-7ba0-4ac5-8fb8-a8e1ac7a6f7e
+
+# %[1]s every version of a project:
+trivy dependencytrack project %[2]s --project-name my-project --project-version '*' --yes
+`, strings.ToUpper(action[:1])+action[1:], action),
+	}
+
+	addProjectLifecycleFlags(cmd)
+
+	return cmd
+}
+
+func NewProjectUpdateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update [flags]",
+		Short: "Update the description, classifier, group, tags and properties of DependencyTrack projects",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			changes, err := projectChanges()
+			if err != nil {
+				logger.Default().Error("Error validating fields", "error", err)
+				return exit.Config(err)
+			}
+			return runProjectLifecycle(cmd, "update", false, func(ctx context.Context, client *dtrack.Client, p dtrack.Project) error {
+				changed, err := project.Update(ctx, client, p, changes)
+				if err != nil {
+					return err
+				}
+				if len(changed) == 0 {
+					logger.Default().Info("Project unchanged", "uuid", p.UUID, "name", p.Name, "version", p.Version)
+					return nil
+				}
+				logger.Default().Info("Project updated", "uuid", p.UUID, "name", p.Name, "version", p.Version, "changes", changed)
+				return nil
+			})
+		},
+		Example: `
+# Describe and tag a project version:
+trivy dependencytrack project update --project-name my-project --project-version main \
+  --description "Customer API" --classifier APPLICATION --tags team-a,prod
+
+# Set a property on every version of a project:
+trivy dependencytrack project update --project-name my-project --project-version '*' --property ci/owner=team-a --yes
+`,
+	}
+
+	addProjectLifecycleFlags(cmd)
+
+	cmd.Flags().String(common.VDescription, common.VDescriptionDefault, common.VDescriptionUsage)
+	err := viper.BindPFlag(common.VDescription, cmd.Flags().Lookup(common.VDescriptionLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VClassifier, common.VClassifierDefault, common.VClassifierUsage)
+	err = viper.BindPFlag(common.VClassifier, cmd.Flags().Lookup(common.VClassifierLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VGroup, common.VGroupDefault, common.VGroupUsage)
+	err = viper.BindPFlag(common.VGroup, cmd.Flags().Lookup(common.VGroupLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().StringSlice(common.VTags, nil, common.VTagsUsage)
+	err = viper.BindPFlag(common.VTags, cmd.Flags().Lookup(common.VTagsLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().StringArray(common.VProperty, nil, common.VPropertyUsage)
+	err = viper.BindPFlag(common.VProperty, cmd.Flags().Lookup(common.VPropertyLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	return cmd
+}
+
+// addProjectLifecycleFlags registers the flags shared by the commands changing projects.
+func addProjectLifecycleFlags(cmd *cobra.Command) {
+	addServerFlags(cmd)
+	addProjectFlags(cmd)
+
+	// Not bound to viper, a confirmation is only skipped on the command line.
+	cmd.Flags().BoolP(common.VYesLong, common.VYesShort, common.VYesDefault, common.VYesUsage)
+}
+
+// projectChanges reads the fields to update. Only the fields explicitly set are changed, so that a field can be
+// cleared with an empty value.
+func projectChanges() (project.Changes, error) {
+	var changes project.Changes
+	optional := func(key string) *string {
+		if !viper.IsSet(key) {
+			return nil
+		}
+		value := viper.GetString(key)
+		return &value
+	}
+	changes.Description = optional(common.VDescription)
+	changes.Group = optional(common.VGroup)
+	if classifier := optional(common.VClassifier); classifier != nil {
+		value := strings.ToUpper(*classifier)
+		changes.Classifier = &value
+	}
+	if viper.IsSet(common.VTags) {
+		tags := viper.GetStringSlice(common.VTags)
+		changes.Tags = &tags
+	}
+	for _, s := range viper.GetStringSlice(common.VProperty) {
+		prop, err := project.ParseProperty(s)
+		if err != nil {
+			return changes, err
+		}
+		changes.Properties = append(changes.Properties, prop)
+	}
+
+	if changes.Empty() {
+		return changes, fmt.Errorf("nothing to update, set at least one of --%s, --%s, --%s, --%s or --%s", common.VDescriptionLong,
+			common.VClassifierLong, common.VGroupLong, common.VTagsLong, common.VPropertyLong)
+	}
+	return changes, changes.Validate()
+}
+
+// runProjectLifecycle applies an action to the selected projects. Batches, selected by a name or version wildcard, are
+// confirmed first, as is every action with confirmAlways.
+func runProjectLifecycle(cmd *cobra.Command, action string, confirmAlways bool, apply func(ctx context.Context, client *dtrack.Client, p dtrack.Project) error) error {
+	server, err := loadServerConfig()
+	if err != nil {
+		logger.Default().Error("Error validating server config", "error", err)
+		return err
+	}
+	ProjectUUID := viper.GetString(common.VProjectUUID)
+	ProjectName := viper.GetString(common.VProjectName)
+	ProjectVersion := viper.GetString(common.VProjectVersion)
+	Yes, err := cmd.Flags().GetBool(common.VYesLong)
+	if err != nil {
+		return err
+	}
+
+	batch := ProjectUUID == "" && (project.IsPattern(ProjectName) || project.IsPattern(ProjectVersion))
+	switch {
+	case batch && ProjectName == "":
+		err = fmt.Errorf("--%s is required with a --%s pattern, use '*' to select every project", common.VProjectNameLong, common.VProjectVersionLong)
+	case !batch && ProjectUUID == "" && ProjectName != "" && ProjectVersion == "":
+		err = fmt.Errorf("--%s is required, use '*' to select every version", common.VProjectVersionLong)
+	}
+	if err != nil {
+		logger.Default().Error("Error validating fields", "error", err)
+		return exit.Config(err)
+	}
+
+	ctx := context.TODO()
+	client, err := newClient(server)
+	if err != nil {
+		logger.Default().Error("Error connecting to dependencytrack", "error", err)
+		return err
+	}
+
+	var projects []dtrack.Project
+	if batch {
+		projects, err = project.Match(ctx, client, ProjectName, ProjectVersion)
+	} else {
+		var p dtrack.Project
+		p, err = resolveProject(ctx, client, ProjectUUID, ProjectName, ProjectVersion)
+		projects = []dtrack.Project{p}
+	}
+	if err != nil {
+		logger.Default().Error("Error resolving project", "error", err)
+		return err
+	}
+	if len(projects) == 0 {
+		logger.Default().Warn("No project matches", "name", ProjectName, "version", ProjectVersion)
+		return nil
+	}
+
+	if (batch || confirmAlways) && !Yes {
+		ok, err := confirm(action, projects)
+		if err != nil {
+			logger.Default().Error("Error confirming "+action, "error", err)
+			return err
+		}
+		if !ok {
+			logger.Default().Info("Aborted, nothing changed")
+			return nil
+		}
+	}
+
+	for _, p := range projects {
+		err = apply(ctx, client, p)
+		if err != nil {
+			logger.Default().Error("Error during project "+action, "uuid", p.UUID, "name", p.Name, "version", p.Version, "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// confirm lists the projects on stderr and asks whether to go on. It refuses when stdin is not a terminal, a pipeline
+// has to pass --yes.
+func confirm(action string, projects []dtrack.Project) (bool, error) {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false, fmt.Errorf("cannot ask for confirmation, stdin is not a terminal: pass --%s to %s %d projects", common.VYesLong, action, len(projects))
+	}
+
+	err = project.Table(os.Stderr, projects)
+	if err != nil {
+		return false, err
+	}
+	fmt.Fprintf(os.Stderr, "\n%s %d projects? [y/N] ", strings.ToUpper(action[:1])+action[1:], len(projects))
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if errors.Is(err, io.EOF) {
+		fmt.Fprintln(os.Stderr)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
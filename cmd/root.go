@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"fmt"
+	"maps"
+	"slices"
+
     "github.com/weeros/trivy-plugin-dependencytrack/cmd/common"
+    "github.com/weeros/trivy-plugin-dependencytrack/pkg/exit"
     "github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
 	"os"
 	"strings"
@@ -15,30 +20,67 @@ var rootCmd = NewRootCommand()
 
 func NewRootCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:               "trivy-plugin-dependencytrack [command]",
-		Short:             "DependencyTrack plugin for Trivy",
-		Long:              `The DependencyTrack plugin for Trivy pushes SBOMs in DependencyTrack Server.`,
+		Use:   "trivy-plugin-dependencytrack [command]",
+		Short: "DependencyTrack plugin for Trivy",
+		Long: `The DependencyTrack plugin for Trivy pushes SBOMs in DependencyTrack Server.
+
+Exit codes:
+  0  success, or upload skipped in this CI context
+  1  unexpected error
+  2  invalid flag, environment variable, config file or setting
+  3  credentials rejected by DependencyTrack, or missing permission
+  4  DependencyTrack unreachable: DNS, connection, TLS or request timeout
+  5  request rejected or failed by DependencyTrack
+  6  sbom, analysis or VEX not processed by DependencyTrack in time
+  7  vulnerability gate failed`,
 		Args:              cobra.MaximumNArgs(1),
-		SilenceUsage:      false,
-		SilenceErrors:     false,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
 		PersistentPreRunE: preRun,
 	}
 
 	cmd.AddCommand(NewUploadCommand())
 	cmd.AddCommand(NewUploadGitlabCommand())
+	cmd.AddCommand(NewUploadGithubCommand())
+	cmd.AddCommand(NewFindingsCommand())
+	cmd.AddCommand(NewVexCommand())
+	cmd.AddCommand(NewTriageCommand())
+	cmd.AddCommand(NewProjectCommand())
+	cmd.AddCommand(NewMetricsCommand())
+	cmd.AddCommand(NewAnalyzeCommand())
+	cmd.AddCommand(NewConfigCommand())
+	cmd.AddCommand(NewDoctorCommand())
+
+	cmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return exit.Config(err)
+	})
 
 	return cmd
 }
 
+// pluginVersion is the version of the plugin, sent in the User-Agent of the requests to DependencyTrack.
+var pluginVersion = "dev"
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
-func Execute() {
+func Execute(version string) {
+	pluginVersion = version
+	rootCmd.Version = version
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		// Cobra is silenced: the commands log their errors with their context once running, the earlier ones, i.e.
+		// invalid flags, arguments or config, are logged here.
+		if !commandRunning {
+			err = exit.Config(err)
+			logger.Default().Error("Error running command", "error", err, "help", "run with --help for usage")
+		}
+		os.Exit(exit.Code(err))
 	}
 }
 
+// commandRunning is set once the pre-run of the command succeeded, the command then logging its own errors.
+var commandRunning bool
+
 
 func init() {
 	cobra.OnInitialize(initConfig)
@@ -50,6 +92,13 @@ func init() {
 		os.Exit(1)
 	}
 
+	rootCmd.PersistentFlags().String(common.VProfileLong, common.VProfileDefault, common.VProfileUsage)
+	err = viper.BindPFlag(common.VProfile, rootCmd.PersistentFlags().Lookup(common.VProfileLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
 	rootCmd.PersistentFlags().StringP(common.VLogLevelLong, common.VLogLevelShort, common.VLogLevelDefault, common.VLogLevelUsage)
 	err = viper.BindPFlag(common.VLogLevel, rootCmd.PersistentFlags().Lookup(common.VLogLevelLong))
 	if err != nil {
@@ -84,7 +133,7 @@ func initConfig() {
 		err := common.ValidateConfig(configFile)
 		if err != nil {
 			logger.Default().Error("Error validating config file", "cfgFile", configFile, "err", err)
-			os.Exit(1)
+			os.Exit(int(exit.KindConfig))
 		}
 		viper.SetConfigFile(configFile)
 	} else {
@@ -107,23 +156,67 @@ func initConfig() {
 	} else {
 		logger.Default().Error("Error reading config file", "err", err)
 		logger.Default().Error("Use --help flag for more information")
-		os.Exit(1)
+		os.Exit(int(exit.KindConfig))
+	}
+
+	err = applyProfile(viper.GetString(common.VProfile))
+	if err != nil {
+		logger.Default().Error("Error applying config profile", "err", err)
+		os.Exit(int(exit.KindConfig))
 	}
 }
 
+// applyProfile merges the keys of a config file profile over the top-level ones. Flags and environment variables still
+// take precedence over the profile.
+func applyProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+	if viper.ConfigFileUsed() == "" {
+		return fmt.Errorf("profile %q selected but no config file was found", name)
+	}
+	profiles := viper.GetStringMap(common.VProfiles)
+	profile, ok := profiles[name]
+	if !ok {
+		names := slices.Sorted(maps.Keys(profiles))
+		return fmt.Errorf("profile %q not found in config file %q, available profiles: %v", name, viper.ConfigFileUsed(), names)
+	}
+	values, ok := profile.(map[string]any)
+	if !ok {
+		return fmt.Errorf("profile %q must be a map of config keys", name)
+	}
+	err := viper.MergeConfigMap(values)
+	if err != nil {
+		return err
+	}
+	logger.Default().Info("Using config profile", "profile", name, "keys", slices.Sorted(maps.Keys(values)))
+	return nil
+}
+
 
 
 func preRun(cmd *cobra.Command, _ []string) error {
+	// Several commands register the same flags, so bind the keys again to the flags of the command being run.
+	err := viper.BindPFlags(cmd.Flags())
+	if err != nil {
+		return err
+	}
+
 	l, err := setupLogger(
 		viper.GetString(common.VLogLevel),
 		viper.GetString(common.VLogFormat),
 		!viper.GetBool(common.VNoColor),
 	)
 	if err != nil {
-		return err
+		return exit.Config(err)
 	}
 	ctx := logger.WithContext(cmd.Context(), l)
 	cmd.SetContext(ctx)
+	err = loadSecretFiles()
+	if err != nil {
+		return exit.Config(err)
+	}
+	commandRunning = true
 	return nil
 }
 
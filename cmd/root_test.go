@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/cmd/common"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const profilesConfig = `
+url-api: https://dt.example.com
+project-name: top
+timeout: 30s
+profiles:
+  staging:
+    url-api: https://staging.example.com
+    timeout: 2m
+  broken: nope
+`
+
+// loadTestConfig resets viper and reads config as initConfig does, with args parsed as flags bound to viper.
+func loadTestConfig(t *testing.T, config string, args ...string) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetEnvPrefix(common.VEnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String(common.VUrlApiLong, "", "")
+	flags.Duration(common.VTimeoutLong, 0, "")
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	if err := viper.BindPFlags(flags); err != nil {
+		t.Fatal(err)
+	}
+
+	if config == "" {
+		return
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		profile     string
+		args        []string
+		env         string
+		wantURL     string
+		wantTimeout time.Duration
+	}{
+		{name: "no profile", wantURL: "https://dt.example.com", wantTimeout: 30 * time.Second},
+		{name: "profile over the file", profile: "staging", wantURL: "https://staging.example.com", wantTimeout: 2 * time.Minute},
+		{
+			name:        "flag over the profile",
+			profile:     "staging",
+			args:        []string{"--timeout", "5m"},
+			wantURL:     "https://staging.example.com",
+			wantTimeout: 5 * time.Minute,
+		},
+		{
+			name:        "env over the profile",
+			profile:     "staging",
+			env:         "https://env.example.com",
+			wantURL:     "https://env.example.com",
+			wantTimeout: 2 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv("TRIVY_PLUGIN_DEPENDENCYTRACK_URL_API", tt.env)
+			}
+			loadTestConfig(t, profilesConfig, tt.args...)
+
+			if err := applyProfile(tt.profile); err != nil {
+				t.Fatalf("applyProfile() error = %v", err)
+			}
+			if got := viper.GetString(common.VUrlApi); got != tt.wantURL {
+				t.Errorf("url-api = %q, want %q", got, tt.wantURL)
+			}
+			if got := viper.GetDuration(common.VTimeout); got != tt.wantTimeout {
+				t.Errorf("timeout = %s, want %s", got, tt.wantTimeout)
+			}
+			if got := viper.GetString(common.VProjectName); got != "top" {
+				t.Errorf("project-name = %q, want the top-level value", got)
+			}
+		})
+	}
+}
+
+func TestApplyProfileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		profile string
+		wantErr []string
+	}{
+		{
+			name:    "unknown profile",
+			config:  profilesConfig,
+			profile: "prod",
+			wantErr: []string{`profile "prod" not found in config file`, "available profiles: [broken staging]"},
+		},
+		{
+			name:    "profile not a map",
+			config:  profilesConfig,
+			profile: "broken",
+			wantErr: []string{`profile "broken" must be a map of config keys`},
+		},
+		{
+			name:    "no config file",
+			profile: "staging",
+			wantErr: []string{`profile "staging" selected but no config file was found`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loadTestConfig(t, tt.config)
+
+			err := applyProfile(tt.profile)
+			if err == nil {
+				t.Fatalf("applyProfile() error = nil, want %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("applyProfile() error = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
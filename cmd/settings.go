@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/cmd/common"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/config"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/exit"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/secret"
+
+	"github.com/spf13/viper"
+)
+
+// loadConfig decodes the shared settings merged by viper from the flags, environment variables and config file, and
+// reads the API key from its file or command. The API key, proxy password and secret header values are redacted from
+// the logs from then on.
+func loadConfig() (config.Config, error) {
+	var cfg config.Config
+	err := viper.Unmarshal(&cfg)
+	if err != nil {
+		return config.Config{}, exit.Config(fmt.Errorf("failed to decode config: %w", err))
+	}
+	err = cfg.Server.ResolveAPIKey(context.TODO())
+	if err != nil {
+		return config.Config{}, exit.Config(err)
+	}
+	logger.RedactSecret(cfg.APIKey)
+	// Proxy credentials and gateway headers are often secrets too.
+	if u, err := url.Parse(cfg.Proxy); err == nil && u.User != nil {
+		password, _ := u.User.Password()
+		logger.RedactSecret(password)
+	}
+	for _, header := range cfg.Headers {
+		name, value, err := config.ParseHeader(header)
+		if err == nil && cfg.SecretHeader(name) {
+			logger.RedactSecret(value)
+		}
+	}
+	return cfg, nil
+}
+
+// loadSecretFiles sets the secrets given through *_FILE environment variables as defaults, so that the flags,
+// environment variables and config file still take precedence, and redacts every secret from the logs.
+func loadSecretFiles() error {
+	files, err := secret.ReadEnvFiles(common.VEnvPrefix)
+	if err != nil {
+		return err
+	}
+	if files.GitlabToken != "" {
+		viper.SetDefault(common.VGitlabToken, files.GitlabToken)
+	}
+	if files.Password != "" {
+		viper.SetDefault(common.VPassword, files.Password)
+	}
+	for _, key := range secretConfigKeys {
+		logger.RedactSecret(viper.GetString(key))
+	}
+	return nil
+}
+
+// loadServerConfig loads the settings to reach DependencyTrack and reports all their problems at once.
+func loadServerConfig() (config.Server, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return config.Server{}, err
+	}
+	err = cfg.Server.Validate()
+	if err != nil {
+		return config.Server{}, exit.Config(err)
+	}
+	return cfg.Server, nil
+}
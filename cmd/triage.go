@@ -0,0 +1,541 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/cmd/common"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/config"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/exit"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/triage"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+func NewTriageCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "triage [command]",
+		Short: "Record analysis decisions on DependencyTrack findings",
+	}
+
+	cmd.AddCommand(NewTriageSetCommand())
+	cmd.AddCommand(NewTriageImportTrivyIgnoreCommand())
+	cmd.AddCommand(NewTriageApplyRulesCommand())
+
+	return cmd
+}
+
+func NewTriageImportTrivyIgnoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import-trivyignore [flags]",
+		Short: "Import .trivyignore / .trivyignore.yaml entries as DependencyTrack suppressions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := loadServerConfig()
+			if err != nil {
+				logger.Default().Error("Error validating server config", "error", err)
+				return err
+			}
+			ProjectUUID := viper.GetString(common.VProjectUUID)
+			ProjectName := viper.GetString(common.VProjectName)
+			ProjectVersion := viper.GetString(common.VProjectVersion)
+			TrivyIgnore := viper.GetString(common.VTrivyIgnore)
+			DryRun := viper.GetBool(common.VDryRun)
+			if TrivyIgnore == "" {
+				var err error
+				TrivyIgnore, err = triage.FindIgnoreFile(".")
+				if err != nil {
+					logger.Default().Error("Error finding trivyignore file", "error", err)
+					return err
+				}
+			}
+
+			ignore, err := loadTrivyIgnore(TrivyIgnore)
+			if err != nil {
+				logger.Default().Error("Error parsing trivyignore file", "error", err)
+				return err
+			}
+
+			ctx := context.TODO()
+			client, err := newClient(server)
+			if err != nil {
+				logger.Default().Error("Error connecting to dependencytrack", "error", err)
+				return err
+			}
+			project, err := resolveProject(ctx, client, ProjectUUID, ProjectName, ProjectVersion)
+			if err != nil {
+				logger.Default().Error("Error resolving project", "error", err)
+				return err
+			}
+			err = importTrivyIgnore(ctx, client, project, ignore, DryRun)
+			if err != nil {
+				logger.Default().Error("Error importing trivyignore file", "error", err)
+				return err
+			}
+			return nil
+		},
+		Example: `
+# Import the .trivyignore.yaml (or .trivyignore) of the current directory:
+trivy dependencytrack triage import-trivyignore --project-name my-project --project-version main
+
+# Preview the changes of an explicit file:
+trivy dependencytrack triage import-trivyignore --project-name my-project --project-version main \
+  --trivyignore ./security/.trivyignore.yaml --trivyignore-justification CODE_NOT_REACHABLE --dry-run
+`,
+	}
+
+	addServerFlags(cmd)
+	addProjectFlags(cmd)
+	addTrivyIgnoreFlags(cmd)
+
+	cmd.Flags().Bool(common.VDryRun, common.VDryRunDefault, common.VDryRunUsage)
+	err := viper.BindPFlag(common.VDryRun, cmd.Flags().Lookup(common.VDryRunLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	return cmd
+}
+
+func NewTriageSetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set [flags]",
+		Short: "Set the analysis of findings of a project",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := loadServerConfig()
+			if err != nil {
+				logger.Default().Error("Error validating server config", "error", err)
+				return err
+			}
+			ProjectUUID := viper.GetString(common.VProjectUUID)
+			ProjectName := viper.GetString(common.VProjectName)
+			ProjectVersion := viper.GetString(common.VProjectVersion)
+			File := viper.GetString(common.VTriageFile)
+			DryRun := viper.GetBool(common.VDryRun)
+
+			decisions, err := triageSetDecisions(File)
+			if err != nil {
+				logger.Default().Error("Error validating analysis", "error", err)
+				return exit.Config(err)
+			}
+
+			ctx := context.TODO()
+			client, err := newClient(server)
+			if err != nil {
+				logger.Default().Error("Error connecting to dependencytrack", "error", err)
+				return err
+			}
+			project, err := resolveProject(ctx, client, ProjectUUID, ProjectName, ProjectVersion)
+			if err != nil {
+				logger.Default().Error("Error resolving project", "error", err)
+				return err
+			}
+			findings, err := triage.FetchFindings(ctx, client, project.UUID)
+			if err != nil {
+				logger.Default().Error("Error fetching findings", "error", err)
+				return err
+			}
+			results, err := triage.Apply(ctx, client, project.UUID, findings, decisions, DryRun)
+			if err != nil {
+				logger.Default().Error("Error recording analysis", "error", err)
+				return err
+			}
+
+			logger.Default().Info("Analyses recorded", "project", project.Name, "version", project.Version,
+				"decisions", len(decisions), "dryRun", DryRun)
+			return triage.WriteResults(os.Stdout, results)
+		},
+		Example: `
+# Mark a vulnerability as not affecting every version of a package, and suppress it:
+trivy dependencytrack triage set --project-name my-project --project-version main \
+  --component pkg:golang/golang.org/x/net --vulnerability CVE-2023-44487 \
+  --state NOT_AFFECTED --justification CODE_NOT_REACHABLE --details "HTTP/2 server not used" --suppress
+
+# Record the analyses listed in a CSV or YAML file:
+trivy dependencytrack triage set --project-uuid 5e0f5fd4-7ba0-4ac5-8fb8-a8e1ac7a6f7e --file ./analyses.csv --dry-run
+`,
+	}
+
+	addServerFlags(cmd)
+	addProjectFlags(cmd)
+
+	cmd.Flags().String(common.VComponent, common.VComponentDefault, common.VComponentUsage)
+	err := viper.BindPFlag(common.VComponent, cmd.Flags().Lookup(common.VComponentLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VVulnerability, common.VVulnerabilityDefault, common.VVulnerabilityUsage)
+	err = viper.BindPFlag(common.VVulnerability, cmd.Flags().Lookup(common.VVulnerabilityLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VAnalysisState, common.VAnalysisStateDefault, common.VAnalysisStateUsage)
+	err = viper.BindPFlag(common.VAnalysisState, cmd.Flags().Lookup(common.VAnalysisStateLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VAnalysisJustification, common.VAnalysisJustificationDefault, common.VAnalysisJustificationUsage)
+	err = viper.BindPFlag(common.VAnalysisJustification, cmd.Flags().Lookup(common.VAnalysisJustificationLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VAnalysisResponse, common.VAnalysisResponseDefault, common.VAnalysisResponseUsage)
+	err = viper.BindPFlag(common.VAnalysisResponse, cmd.Flags().Lookup(common.VAnalysisResponseLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VAnalysisDetails, common.VAnalysisDetailsDefault, common.VAnalysisDetailsUsage)
+	err = viper.BindPFlag(common.VAnalysisDetails, cmd.Flags().Lookup(common.VAnalysisDetailsLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VAnalysisComment, common.VAnalysisCommentDefault, common.VAnalysisCommentUsage)
+	err = viper.BindPFlag(common.VAnalysisComment, cmd.Flags().Lookup(common.VAnalysisCommentLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().Bool(common.VSuppress, common.VSuppressDefault, common.VSuppressUsage)
+	err = viper.BindPFlag(common.VSuppress, cmd.Flags().Lookup(common.VSuppressLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VTriageFile, common.VTriageFileDefault, common.VTriageFileUsage)
+	err = viper.BindPFlag(common.VTriageFile, cmd.Flags().Lookup(common.VTriageFileLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().Bool(common.VDryRun, common.VDryRunDefault, common.VDryRunUsage)
+	err = viper.BindPFlag(common.VDryRun, cmd.Flags().Lookup(common.VDryRunLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	return cmd
+}
+
+// triageSetDecisions returns the decisions of the bulk file when given, otherwise the one described by the flags.
+func triageSetDecisions(file string) ([]triage.Decision, error) {
+	if file != "" {
+		if viper.GetString(common.VComponent) != "" || viper.GetString(common.VVulnerability) != "" {
+			return nil, fmt.Errorf("--%s cannot be combined with --%s or --%s", common.VTriageFileLong, common.VComponentLong, common.VVulnerabilityLong)
+		}
+		return triage.ParseBulkFile(file)
+	}
+
+	entry := triage.Entry{
+		Component:     viper.GetString(common.VComponent),
+		Vulnerability: viper.GetString(common.VVulnerability),
+		State:         viper.GetString(common.VAnalysisState),
+		Justification: viper.GetString(common.VAnalysisJustification),
+		Response:      viper.GetString(common.VAnalysisResponse),
+		Details:       viper.GetString(common.VAnalysisDetails),
+		Comment:       viper.GetString(common.VAnalysisComment),
+		Suppressed:    viper.GetBool(common.VSuppress),
+	}
+	d, err := entry.Decision()
+	if err != nil {
+		return nil, err
+	}
+	return []triage.Decision{d}, nil
+}
+
+// addTrivyIgnoreFlags registers the trivyignore import flags, shared with the upload commands.
+func addTrivyIgnoreFlags(cmd *cobra.Command) {
+	cmd.Flags().String(common.VTrivyIgnore, common.VTrivyIgnoreDefault, common.VTrivyIgnoreUsage)
+	err := viper.BindPFlag(common.VTrivyIgnore, cmd.Flags().Lookup(common.VTrivyIgnoreLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VTrivyIgnoreState, common.VTrivyIgnoreStateDefault, common.VTrivyIgnoreStateUsage)
+	err = viper.BindPFlag(common.VTrivyIgnoreState, cmd.Flags().Lookup(common.VTrivyIgnoreStateLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VTrivyIgnoreJustification, common.VTrivyIgnoreJustificationDefault, common.VTrivyIgnoreJustificationUsage)
+	err = viper.BindPFlag(common.VTrivyIgnoreJustification, cmd.Flags().Lookup(common.VTrivyIgnoreJustificationLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+}
+
+// importTrivyIgnore records the entries of a trivy ignore file on the findings of a project and prints what changed.
+// trivyIgnore is a parsed trivyignore file and the analyses recorded for its entries.
+type trivyIgnore struct {
+	file      string
+	entries   int
+	decisions []triage.Decision
+}
+
+// loadTrivyIgnore parses a trivyignore file with the state and justification recorded for its entries, so that an
+// invalid file is reported before anything is sent to DependencyTrack.
+func loadTrivyIgnore(file string) (trivyIgnore, error) {
+	state, err := triage.ParseState(viper.GetString(common.VTrivyIgnoreState))
+	if err != nil {
+		return trivyIgnore{}, exit.Config(err)
+	}
+	justification, err := triage.ParseJustification(viper.GetString(common.VTrivyIgnoreJustification))
+	if err != nil {
+		return trivyIgnore{}, exit.Config(err)
+	}
+
+	entries, err := triage.ParseIgnoreFile(file)
+	if err != nil {
+		return trivyIgnore{}, exit.Config(fmt.Errorf("failed to parse %s: %w", file, err))
+	}
+	decisions, unmapped := triage.IgnoreDecisions(entries, filepath.Base(file), state, justification, time.Now())
+	for _, e := range unmapped {
+		logger.Default().Warn("Skipping entry restricted to paths, DependencyTrack findings have no path", "id", e.ID, "paths", e.Paths)
+	}
+	return trivyIgnore{file: file, entries: len(entries), decisions: decisions}, nil
+}
+
+func importTrivyIgnore(ctx context.Context, client *dtrack.Client, project dtrack.Project, ignore trivyIgnore, dryRun bool) error {
+	decisions := ignore.decisions
+	findings, err := triage.FetchFindings(ctx, client, project.UUID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch findings: %w", err)
+	}
+	results, err := triage.Apply(ctx, client, project.UUID, findings, decisions, dryRun)
+	if err != nil {
+		return err
+	}
+	if len(decisions) > 0 && !slices.ContainsFunc(results, func(r triage.Result) bool { return r.Action != triage.ActionNoMatch }) {
+		logger.Default().Warn("No finding matched the trivyignore entries, the project may not be analysed yet",
+			"project", project.Name, "version", project.Version, "findings", len(findings))
+	}
+
+	logger.Default().Info("Trivyignore imported", "file", ignore.file, "project", project.Name, "version", project.Version,
+		"entries", ignore.entries, "dryRun", dryRun)
+	return triage.WriteResults(os.Stdout, results)
+}
+
+// loadUploadTrivyIgnore parses the trivyignore file given to an upload command, if any, before the upload.
+func loadUploadTrivyIgnore() (trivyIgnore, error) {
+	file := viper.GetString(common.VTrivyIgnore)
+	if file == "" {
+		return trivyIgnore{}, nil
+	}
+	return loadTrivyIgnore(file)
+}
+
+// importTrivyIgnoreAfterUpload applies the trivyignore file parsed by loadUploadTrivyIgnore, if any.
+func importTrivyIgnoreAfterUpload(server config.Server, projectName string, projectVersion string, ignore trivyIgnore) error {
+	if ignore.file == "" {
+		return nil
+	}
+	ctx := context.TODO()
+	client, err := newClient(server)
+	if err != nil {
+		return err
+	}
+	project, err := resolveProject(ctx, client, "", projectName, projectVersion)
+	if err != nil {
+		return err
+	}
+	return importTrivyIgnore(ctx, client, project, ignore, false)
+}
+
+func NewTriageApplyRulesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply-rules [flags]",
+		Short: "Apply the auto-triage rules to the findings of a project",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := loadServerConfig()
+			if err != nil {
+				logger.Default().Error("Error validating server config", "error", err)
+				return err
+			}
+			ProjectUUID := viper.GetString(common.VProjectUUID)
+			ProjectName := viper.GetString(common.VProjectName)
+			ProjectVersion := viper.GetString(common.VProjectVersion)
+			BomFile := viper.GetString(common.VBomFile)
+			DryRun := viper.GetBool(common.VDryRun)
+
+			rules, err := loadTriageRules()
+			if err != nil {
+				logger.Default().Error("Error loading triage rules", "error", err)
+				return exit.Config(err)
+			}
+			if rules.Len() == 0 {
+				err := fmt.Errorf("no triage rules, set %s in the config file or --%s", common.VTriageRules, common.VTriageRulesFileLong)
+				logger.Default().Error("Error loading triage rules", "error", err)
+				return exit.Config(err)
+			}
+
+			ctx := context.TODO()
+			client, err := newClient(server)
+			if err != nil {
+				logger.Default().Error("Error connecting to dependencytrack", "error", err)
+				return err
+			}
+			project, err := resolveProject(ctx, client, ProjectUUID, ProjectName, ProjectVersion)
+			if err != nil {
+				logger.Default().Error("Error resolving project", "error", err)
+				return err
+			}
+			err = applyTriageRules(ctx, client, project, rules, BomFile, viper.GetBool(common.VTriageRulesOverwrite), DryRun)
+			if err != nil {
+				logger.Default().Error("Error applying triage rules", "error", err)
+				return err
+			}
+			return nil
+		},
+		Example: `
+# Preview the rules of a file on a project, reading component scopes from its sbom:
+trivy dependencytrack triage apply-rules --project-name my-project --project-version main \
+  --triage-rules-file ./triage-rules.yaml --bom-file ./sbom.json --dry-run
+
+# triage-rules.yaml:
+rules:
+  - name: distroless-kernel
+    match:
+      purls: ["pkg:deb/debian/linux-*"]
+    analysis:
+      state: NOT_AFFECTED
+      justification: CODE_NOT_PRESENT
+      details: The kernel is provided by the host
+      suppress: true
+  - name: test-scope
+    match:
+      scopes: [optional, excluded]
+      severities: [low, medium]
+    analysis:
+      state: NOT_AFFECTED
+      justification: CODE_NOT_REACHABLE
+`,
+	}
+
+	addServerFlags(cmd)
+	addProjectFlags(cmd)
+	addTriageRulesFlags(cmd)
+
+	cmd.Flags().String(common.VBomFile, common.VBomFileDefault, common.VBomFileUsage)
+	err := viper.BindPFlag(common.VBomFile, cmd.Flags().Lookup(common.VBomFileLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().Bool(common.VDryRun, common.VDryRunDefault, common.VDryRunUsage)
+	err = viper.BindPFlag(common.VDryRun, cmd.Flags().Lookup(common.VDryRunLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	return cmd
+}
+
+// addTriageRulesFlags registers the auto-triage rules flag, shared with the upload commands.
+func addTriageRulesFlags(cmd *cobra.Command) {
+	cmd.Flags().String(common.VTriageRulesFile, common.VTriageRulesFileDefault, common.VTriageRulesFileUsage)
+	err := viper.BindPFlag(common.VTriageRulesFile, cmd.Flags().Lookup(common.VTriageRulesFileLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().Bool(common.VTriageRulesOverwrite, common.VTriageRulesOverwriteDefault, common.VTriageRulesOverwriteUsage)
+	err = viper.BindPFlag(common.VTriageRulesOverwrite, cmd.Flags().Lookup(common.VTriageRulesOverwriteLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+}
+
+// loadTriageRules reads the triage-rules of the config file followed by those of the rules file, if any.
+func loadTriageRules() (*triage.RuleSet, error) {
+	var rules []triage.Rule
+	err := viper.UnmarshalKey(common.VTriageRules, &rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", common.VTriageRules, err)
+	}
+	if file := viper.GetString(common.VTriageRulesFile); file != "" {
+		fileRules, err := triage.ParseRulesFile(file)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, fileRules...)
+	}
+	return triage.NewRuleSet(rules)
+}
+
+// applyTriageRules records the analysis of the first matching rule on each finding of a project and prints every
+// decision. Scope conditions only match when the sbom of the project is given. Analyses recorded by hand are kept
+// unless overwrite is set.
+func applyTriageRules(ctx context.Context, client *dtrack.Client, project dtrack.Project, rules *triage.RuleSet, bomFile string, overwrite bool, dryRun bool) error {
+	var scopes map[string]string
+	if bomFile != "" {
+		content, err := os.ReadFile(bomFile)
+		if err != nil {
+			return err
+		}
+		scopes, err = triage.BOMScopes(content)
+		if err != nil {
+			return fmt.Errorf("failed to read component scopes of %s: %w", bomFile, err)
+		}
+	}
+
+	findings, err := triage.FetchFindings(ctx, client, project.UUID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch findings: %w", err)
+	}
+	decisions := rules.Decisions(findings, scopes, overwrite)
+	results, err := triage.Apply(ctx, client, project.UUID, findings, decisions, dryRun)
+	if err != nil {
+		return err
+	}
+
+	logger.Default().Info("Triage rules applied", "project", project.Name, "version", project.Version,
+		"rules", rules.Len(), "findings", len(findings), "decisions", len(decisions), "dryRun", dryRun)
+	return triage.WriteResults(os.Stdout, results)
+}
+
+// applyTriageRulesAfterUpload applies the auto-triage rules loaded for an upload command by loadTriageRules, if any.
+func applyTriageRulesAfterUpload(server config.Server, projectName string, projectVersion string, rules *triage.RuleSet, bomFile string) error {
+	if rules.Len() == 0 {
+		return nil
+	}
+	ctx := context.TODO()
+	client, err := newClient(server)
+	if err != nil {
+		return err
+	}
+	project, err := resolveProject(ctx, client, "", projectName, projectVersion)
+	if err != nil {
+		return err
+	}
+	return applyTriageRules(ctx, client, project, rules, bomFile, viper.GetBool(common.VTriageRulesOverwrite), false)
+}
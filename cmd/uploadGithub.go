@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/cmd/common"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/ci"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/exit"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/report"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewUploadGithubCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upload-github [flags]",
+		Short: "Upload a sbom to DependencyTrack in GitHub Actions context",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				logger.Default().Error("Error loading config", "error", err)
+				return err
+			}
+			server := cfg.Server
+			AutoCreate := cfg.AutoCreate
+			BomFile := cfg.BOMFile
+			githubPush := viper.GetBool(common.VGithubPush)
+			githubTag := viper.GetBool(common.VGithubTag)
+			githubPR := viper.GetBool(common.VGithubPR)
+			githubDispatch := viper.GetBool(common.VGithubDispatch)
+			githubStepSummary := viper.GetBool(common.VGithubStepSummary)
+			diff, diffEnabled := uploadDiffOptions()
+			if diff.BaselineUUID == "" && diff.BaselineVersion == "" {
+				diff.BaselineVersion = ci.GitHub{}.Context(os.Getenv).Baseline()
+			}
+			stepSummary := githubStepSummary && os.Getenv("GITHUB_STEP_SUMMARY") != ""
+			github, skip := validationGithub(githubPush, githubTag, githubPR, githubDispatch)
+			if skip != "" {
+				logger.Default().Info("Upload skipped", "reason", skip)
+				return nil
+			}
+			ProjectName, ProjectVersion, err := resolveProjectNaming(cfg.ProjectName, cfg.ProjectVersion, github, BomFile)
+			if err != nil {
+				logger.Default().Error("Error resolving project name and version", "error", err)
+				return exit.Config(err)
+			}
+			if ProjectName == "" {
+				ProjectName = github.ProjectName
+			}
+			if ProjectVersion == "" {
+				ProjectVersion = github.Version
+			}
+			cfg.ProjectName, cfg.ProjectVersion = ProjectName, ProjectVersion
+			err = cfg.ValidateUpload()
+			if err != nil {
+				logger.Default().Error("Error validating fields", "error", err)
+				return exit.Config(err)
+			}
+			err = diff.Gate.Validate()
+			if err != nil {
+				logger.Default().Error("Error validating fields", "error", err)
+				return exit.Config(err)
+			}
+			ignore, err := loadUploadTrivyIgnore()
+			if err != nil {
+				logger.Default().Error("Error parsing trivyignore file", "error", err)
+				return err
+			}
+			rules, err := loadTriageRules()
+			if err != nil {
+				logger.Default().Error("Error loading triage rules", "error", err)
+				return exit.Config(err)
+			}
+			uploadedAt := time.Now()
+			err = upload(server, ProjectName, ProjectVersion, AutoCreate, BomFile)
+			if err != nil {
+				logger.Default().Error("Error uploading DependencyTrack sbom", "error", err)
+				return err
+			}
+			err = waitForAnalysisAfterUpload(server, ProjectName, ProjectVersion, uploadedAt, findingsReaders(map[string]bool{
+				"findings diff":      diffEnabled,
+				"step summary":       stepSummary,
+				"trivyignore import": ignore.file != "",
+				"triage rules":       rules.Len() > 0,
+			}))
+			if err != nil {
+				logger.Default().Error("Error waiting for vulnerability analysis", "error", err)
+				return err
+			}
+			err = importTrivyIgnoreAfterUpload(server, ProjectName, ProjectVersion, ignore)
+			if err != nil {
+				logger.Default().Error("Error importing trivyignore file", "error", err)
+				return err
+			}
+			err = applyTriageRulesAfterUpload(server, ProjectName, ProjectVersion, rules, BomFile)
+			if err != nil {
+				logger.Default().Error("Error applying triage rules", "error", err)
+				return err
+			}
+			if !diffEnabled && !stepSummary {
+				return nil
+			}
+			summary, err := summarizeUpload(server, ProjectName, ProjectVersion, diff)
+			if err != nil {
+				logger.Default().Error("Error comparing findings", "error", err)
+				return err
+			}
+			if stepSummary {
+				err = writeGithubStepSummary(summary)
+				if err != nil {
+					logger.Default().Error("Error writing GitHub step summary", "error", err)
+					return err
+				}
+			}
+			if diffEnabled {
+				return checkSummary(summary, diff)
+			}
+			return nil
+		},
+		Example: `
+# Upload a local dependencytrack sbom in GitHub Actions context, named after the repository and versioned by ref:
+trivy dependencytrack upload-github --bom-file ./sbom.json
+
+# Upload tags only, versioned by major.minor, e.g. v1.4.2 uploads 1.4:
+trivy dependencytrack upload-github --bom-file ./sbom.json --github-push=false --github-dispatch=false \
+  --project-version-template '{{(semver .Ref).Major}}.{{(semver .Ref).Minor}}'
+
+# Also upload pull requests, and fail on critical vulnerabilities they introduce compared with the target branch:
+trivy dependencytrack upload-github --bom-file ./sbom.json --github-pr --fail-on-severity critical --gate-introduced-only
+`,
+	}
+
+	addServerFlags(cmd)
+
+	cmd.Flags().String(common.VProjectName, common.VProjectNameDefault, common.VProjectNameUsage)
+	err := viper.BindPFlag(common.VProjectName, cmd.Flags().Lookup(common.VProjectNameLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VProjectVersion, common.VProjectVersionDefault, common.VProjectVersionUsage)
+	err = viper.BindPFlag(common.VProjectVersion, cmd.Flags().Lookup(common.VProjectVersionLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().Bool(common.VAutoCreate, common.VAutoCreateDefault, common.VAutoCreateUsage)
+	err = viper.BindPFlag(common.VAutoCreate, cmd.Flags().Lookup(common.VAutoCreateLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VBomFile, common.VBomFileDefault, common.VBomFileUsage)
+	err = viper.BindPFlag(common.VBomFile, cmd.Flags().Lookup(common.VBomFileLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().Bool(common.VGithubPush, common.VGithubPushDefault, common.VGithubPushUsage)
+	err = viper.BindPFlag(common.VGithubPush, cmd.Flags().Lookup(common.VGithubPushLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().Bool(common.VGithubTag, common.VGithubTagDefault, common.VGithubTagUsage)
+	err = viper.BindPFlag(common.VGithubTag, cmd.Flags().Lookup(common.VGithubTagLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().Bool(common.VGithubPR, common.VGithubPRDefault, common.VGithubPRUsage)
+	err = viper.BindPFlag(common.VGithubPR, cmd.Flags().Lookup(common.VGithubPRLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().Bool(common.VGithubDispatch, common.VGithubDispatchDefault, common.VGithubDispatchUsage)
+	err = viper.BindPFlag(common.VGithubDispatch, cmd.Flags().Lookup(common.VGithubDispatchLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().Bool(common.VGithubStepSummary, common.VGithubStepSummaryDefault, common.VGithubStepSummaryUsage)
+	err = viper.BindPFlag(common.VGithubStepSummary, cmd.Flags().Lookup(common.VGithubStepSummaryLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	addUploadDiffFlags(cmd)
+	addWaitForAnalysisFlags(cmd)
+	addTrivyIgnoreFlags(cmd)
+	addTriageRulesFlags(cmd)
+	addProjectNamingFlags(cmd)
+
+	return cmd
+}
+
+// validationGithub checks that the workflow event is allowed and returns the GitHub Actions context, or the reason to
+// skip the upload. Only push, pull_request and workflow_dispatch events are uploaded: the ref of the other events, such
+// as schedule, release or pull_request_target, does not tell what was built.
+func validationGithub(githubPush, githubTag, githubPR, githubDispatch bool) (ci.Context, string) {
+	github := ci.GitHub{}.Context(os.Getenv)
+	switch event := os.Getenv("GITHUB_EVENT_NAME"); event {
+	case "pull_request":
+		if !githubPR {
+			return github, "pull_request events are not allowed"
+		}
+	case "workflow_dispatch":
+		if !githubDispatch {
+			return github, "workflow_dispatch events are not allowed"
+		}
+	case "push":
+		if github.RefType == ci.RefTag {
+			if !githubTag {
+				return github, "tag pushes are not allowed"
+			}
+		} else if !githubPush {
+			return github, "push events are not allowed"
+		}
+	case "":
+		return github, "GITHUB_EVENT_NAME is not set, not running in GitHub Actions"
+	default:
+		return github, fmt.Sprintf("%s events are not supported, only push, pull_request and workflow_dispatch are uploaded", event)
+	}
+	return github, ""
+}
+
+// writeGithubStepSummary appends the markdown summary to the job summary of the current step.
+func writeGithubStepSummary(summary report.Summary) error {
+	f, err := os.OpenFile(os.Getenv("GITHUB_STEP_SUMMARY"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(report.Markdown(summary) + "\n")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"os"
-	"fmt"
+	"time"
 	"github.com/weeros/trivy-plugin-dependencytrack/cmd/common"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/ci"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/exit"
 	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
 
 	"github.com/spf13/cobra"
@@ -13,59 +15,142 @@ import (
 
 func NewUploadGitlabCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:           "upload-gitlab [flags]",
-		Short:         "Upload a sbom to DependencyTrack in GitLab CI context",
-		SilenceUsage:  false,
-		SilenceErrors: false,
+		Use:   "upload-gitlab [flags]",
+		Short: "Upload a sbom to DependencyTrack in GitLab CI context",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			urlApi := viper.GetString(common.VUrlApi)
-			apikey := viper.GetString(common.VApiKey)
-			AutoCreate := viper.GetBool(common.VAutoCreate)
-			BomFile := viper.GetString(common.VBomFile)
-			ProjectName := viper.GetString(common.VProjectName)
-			ProjectVersion := viper.GetString(common.VProjectVersion)
-			gitlabBranch := viper.GetBool(common.VGitlabBranch)
-			gitlabTag := viper.GetBool(common.VGitlabTag)
-			gitlabMR := viper.GetBool(common.VGitlabMR)
-			ProjectName, ProjectVersion, err := validationGitlab(ProjectName, ProjectVersion, gitlabBranch, gitlabTag, gitlabMR)
+			cfg, err := loadConfig()
+			if err != nil {
+				logger.Default().Error("Error loading config", "error", err)
+				return err
+			}
+			server := cfg.Server
+			AutoCreate := cfg.AutoCreate
+			BomFile := cfg.BOMFile
+			gitlabFilter := ci.Filter{
+				Branches:      viper.GetBool(common.VGitlabBranch),
+				Tags:          viper.GetBool(common.VGitlabTag),
+				MergeRequests: viper.GetBool(common.VGitlabMR),
+				BranchInclude: viper.GetString(common.VGitlabBranchInclude),
+				BranchExclude: viper.GetString(common.VGitlabBranchExclude),
+				TagInclude:    viper.GetString(common.VGitlabTagInclude),
+				TagExclude:    viper.GetString(common.VGitlabTagExclude),
+			}
+			gitlabMRNote := viper.GetBool(common.VGitlabMRNote)
+			gitlabToken := viper.GetString(common.VGitlabToken)
+			diff, diffEnabled := uploadDiffOptions()
+			if diff.BaselineUUID == "" && diff.BaselineVersion == "" {
+				diff.BaselineVersion = ci.GitLab{}.Context(os.Getenv).Baseline()
+			}
+			mrNote := gitlabMRNote && os.Getenv("CI_MERGE_REQUEST_IID") != ""
+			gitlab, skip, err := validationGitlab(gitlabFilter)
 			if err != nil {
 				logger.Default().Error("Error validating GitLab context", "error", err)
+				return exit.Config(err)
+			}
+			if skip != "" {
+				logger.Default().Info("Upload skipped", "reason", skip)
 				return nil
 			}
-			err = validationFields(urlApi, apikey, ProjectName, ProjectVersion, BomFile)
+			ProjectName, ProjectVersion, err := resolveProjectNaming(cfg.ProjectName, cfg.ProjectVersion, gitlab, BomFile)
+			if err != nil {
+				logger.Default().Error("Error resolving project name and version", "error", err)
+				return exit.Config(err)
+			}
+			if ProjectName == "" {
+				ProjectName = gitlab.ProjectName
+			}
+			if ProjectVersion == "" {
+				ProjectVersion = gitlab.Version
+			}
+			cfg.ProjectName, cfg.ProjectVersion = ProjectName, ProjectVersion
+			err = cfg.ValidateUpload()
 			if err != nil {
 				logger.Default().Error("Error validating fields", "error", err)
-				return nil
+				return exit.Config(err)
 			}
-			err = upload(urlApi, apikey, ProjectName, ProjectVersion, AutoCreate, BomFile)
+			err = diff.Gate.Validate()
+			if err != nil {
+				logger.Default().Error("Error validating fields", "error", err)
+				return exit.Config(err)
+			}
+			ignore, err := loadUploadTrivyIgnore()
+			if err != nil {
+				logger.Default().Error("Error parsing trivyignore file", "error", err)
+				return err
+			}
+			rules, err := loadTriageRules()
+			if err != nil {
+				logger.Default().Error("Error loading triage rules", "error", err)
+				return exit.Config(err)
+			}
+			uploadedAt := time.Now()
+			err = upload(server, ProjectName, ProjectVersion, AutoCreate, BomFile)
 			if err != nil {
 				logger.Default().Error("Error uploading DependencyTrack sbom", "error", err)
 				return err
 			}
+			err = waitForAnalysisAfterUpload(server, ProjectName, ProjectVersion, uploadedAt, findingsReaders(map[string]bool{
+				"findings diff":      diffEnabled,
+				"merge request note": mrNote,
+				"trivyignore import": ignore.file != "",
+				"triage rules":       rules.Len() > 0,
+			}))
+			if err != nil {
+				logger.Default().Error("Error waiting for vulnerability analysis", "error", err)
+				return err
+			}
+			err = importTrivyIgnoreAfterUpload(server, ProjectName, ProjectVersion, ignore)
+			if err != nil {
+				logger.Default().Error("Error importing trivyignore file", "error", err)
+				return err
+			}
+			err = applyTriageRulesAfterUpload(server, ProjectName, ProjectVersion, rules, BomFile)
+			if err != nil {
+				logger.Default().Error("Error applying triage rules", "error", err)
+				return err
+			}
+			if !diffEnabled && !mrNote {
+				return nil
+			}
+			summary, err := summarizeUpload(server, ProjectName, ProjectVersion, diff)
+			if err != nil {
+				logger.Default().Error("Error comparing findings", "error", err)
+				return err
+			}
+			if mrNote {
+				err = postGitlabMRNote(gitlabToken, summary)
+				if err != nil {
+					logger.Default().Error("Error posting GitLab merge request note", "error", err)
+					return err
+				}
+			}
+			if diffEnabled {
+				return checkSummary(summary, diff)
+			}
 			return nil
 		},
 		Example: `
 # Upload a local dependencytrack sbom in GitLab CI context:
 trivy dependencytrack upload-gitlab
-`,
-	}
 
-	cmd.Flags().String(common.VUrlApi, common.VUrlApiDefault, common.VUrlApiUsage)
-	err := viper.BindPFlag(common.VUrlApi, cmd.Flags().Lookup(common.VUrlApiLong))
-	if err != nil {
-		logger.Default().Error("Error binding flag to viper", "error", err)
-		os.Exit(1)
-	}
+# Only upload main, release branches and semver tags; other pipelines exit 0 as skipped:
+trivy dependencytrack upload-gitlab --gitlab-branch-include '^(main|release/.+)$' --gitlab-tag-include '^v?[0-9]+\.[0-9]+\.[0-9]+$'
 
-	cmd.Flags().String(common.VApiKey, common.VApiKeyDefault, common.VApiKeyUsage)
-	err = viper.BindPFlag(common.VApiKey, cmd.Flags().Lookup(common.VApiKeyLong))
-	if err != nil {
-		logger.Default().Error("Error binding flag to viper", "error", err)
-		os.Exit(1)
+# Name the project after the group and repository, versioned by slugged ref and short commit:
+trivy dependencytrack upload-gitlab --project-name-template '{{.Namespace}}/{{.Project}}' --project-version-template '{{.Ref | slug}}-{{.ShortSHA}}'
+
+# Also upload merge requests and summarize new and fixed findings on them:
+trivy dependencytrack upload-gitlab --gitlab-mr --gitlab-mr-note --gitlab-token <GITLAB_TOKEN>
+
+# Print the findings introduced since the merge request target branch (or $CI_DEFAULT_BRANCH) and fail on new high vulnerabilities:
+trivy dependencytrack upload-gitlab --fail-on-severity high --gate-introduced-only
+`,
 	}
 
+	addServerFlags(cmd)
+
 	cmd.Flags().String(common.VProjectName, common.VProjectNameDefault, common.VProjectNameUsage)
-	err = viper.BindPFlag(common.VProjectName, cmd.Flags().Lookup(common.VProjectNameLong))
+	err := viper.BindPFlag(common.VProjectName, cmd.Flags().Lookup(common.VProjectNameLong))
 	if err != nil {
 		logger.Default().Error("Error binding flag to viper", "error", err)
 		os.Exit(1)
@@ -92,41 +177,86 @@ trivy dependencytrack upload-gitlab
 		os.Exit(1)
 	}
 
+	cmd.Flags().Bool(common.VGitlabBranch, common.VGitlabBranchDefault, common.VGitlabBranchUsage)
+	err = viper.BindPFlag(common.VGitlabBranch, cmd.Flags().Lookup(common.VGitlabBranchLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
 
-	return cmd
-}
+	cmd.Flags().Bool(common.VGitlabTag, common.VGitlabTagDefault, common.VGitlabTagUsage)
+	err = viper.BindPFlag(common.VGitlabTag, cmd.Flags().Lookup(common.VGitlabTagLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
 
-func validationGitlab(projectName, projectVersion string, gitlabBranch, gitlabTag, gitlabMR bool) (string, string, error) {
-	if os.Getenv("CI_MERGE_REQUEST_IID") != "" && gitlabMR == false {
-		err := fmt.Errorf("Merge Request context not allowed")
-		logger.Default().Error("Abort Import - Running in GitLab Merge Request context not allowed")
-		return	"", "", err
+	cmd.Flags().Bool(common.VGitlabMR, common.VGitlabMRDefault, common.VGitlabMRUsage)
+	err = viper.BindPFlag(common.VGitlabMR, cmd.Flags().Lookup(common.VGitlabMRLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
 	}
 
-	if os.Getenv("CI_COMMIT_BRANCH") != "" && gitlabBranch == false {
-		err := fmt.Errorf("Branch context not allowed")
-		logger.Default().Error("Abort Import - Running in GitLab Branch context not allowed")
-		return "", "", err
+	cmd.Flags().String(common.VGitlabBranchInclude, common.VGitlabBranchIncludeDefault, common.VGitlabBranchIncludeUsage)
+	err = viper.BindPFlag(common.VGitlabBranchInclude, cmd.Flags().Lookup(common.VGitlabBranchIncludeLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
 	}
-	
-	if os.Getenv("CI_COMMIT_TAG") != "" && gitlabTag == false {
-		err := fmt.Errorf("Tag context not allowed")
-		logger.Default().Error("Abort Import - Running in GitLab Tag context not allowed")
-		return "", "", err
+
+	cmd.Flags().String(common.VGitlabBranchExclude, common.VGitlabBranchExcludeDefault, common.VGitlabBranchExcludeUsage)
+	err = viper.BindPFlag(common.VGitlabBranchExclude, cmd.Flags().Lookup(common.VGitlabBranchExcludeLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
 	}
 
-	if projectName == "" {
-		projectName = os.Getenv("CI_PROJECT_TITLE")
+	cmd.Flags().String(common.VGitlabTagInclude, common.VGitlabTagIncludeDefault, common.VGitlabTagIncludeUsage)
+	err = viper.BindPFlag(common.VGitlabTagInclude, cmd.Flags().Lookup(common.VGitlabTagIncludeLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
 	}
-	
-	if projectVersion == "" {
-		projectVersion = os.Getenv("CI_COMMMIT_REF_NAME")
+
+	cmd.Flags().String(common.VGitlabTagExclude, common.VGitlabTagExcludeDefault, common.VGitlabTagExcludeUsage)
+	err = viper.BindPFlag(common.VGitlabTagExclude, cmd.Flags().Lookup(common.VGitlabTagExcludeLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
 	}
 
-	return projectName, projectVersion, nil
-}
+	cmd.Flags().Bool(common.VGitlabMRNote, common.VGitlabMRNoteDefault, common.VGitlabMRNoteUsage)
+	err = viper.BindPFlag(common.VGitlabMRNote, cmd.Flags().Lookup(common.VGitlabMRNoteLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
 
+	cmd.Flags().String(common.VGitlabToken, common.VGitlabTokenDefault, common.VGitlabTokenUsage)
+	err = viper.BindPFlag(common.VGitlabToken, cmd.Flags().Lookup(common.VGitlabTokenLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
 
+	addUploadDiffFlags(cmd)
+	addWaitForAnalysisFlags(cmd)
+	addTrivyIgnoreFlags(cmd)
+	addTriageRulesFlags(cmd)
+	addProjectNamingFlags(cmd)
 
+	return cmd
+}
 
+// validationGitlab checks the filter and returns the GitLab CI context, or the reason to skip the upload when the
+// filter does not select the pipeline.
+func validationGitlab(filter ci.Filter) (ci.Context, string, error) {
+	err := filter.Validate()
+	if err != nil {
+		return ci.Context{}, "", err
+	}
 
+	gitlab := ci.GitLab{}.Context(os.Getenv)
+	return gitlab, filter.Skip(gitlab), nil
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/cmd/common"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/config"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/exit"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/vex"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+func NewVexCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vex [command]",
+		Short: "Exchange VEX documents with DependencyTrack",
+	}
+
+	cmd.AddCommand(NewVexUploadCommand())
+	cmd.AddCommand(NewVexExportCommand())
+
+	return cmd
+}
+
+func NewVexUploadCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upload [flags]",
+		Short: "Upload a CycloneDX VEX document to a DependencyTrack project",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := loadServerConfig()
+			if err != nil {
+				logger.Default().Error("Error validating server config", "error", err)
+				return err
+			}
+			ProjectUUID := viper.GetString(common.VProjectUUID)
+			ProjectName := viper.GetString(common.VProjectName)
+			ProjectVersion := viper.GetString(common.VProjectVersion)
+			VexFile := viper.GetString(common.VVexFile)
+			if VexFile == "" {
+				err := fmt.Errorf("dependencytrack vex-file is required")
+				logger.Default().Error("Error missing dependencytrack vex-file", "error", err)
+				return exit.Config(err)
+			}
+			err = uploadVex(server, ProjectUUID, ProjectName, ProjectVersion, VexFile)
+			if err != nil {
+				logger.Default().Error("Error uploading vex document", "error", err)
+				return err
+			}
+			return nil
+		},
+		Example: `
+# Upload the VEX statements of a product version:
+trivy dependencytrack vex upload --project-name my-project --project-version 1.0.0 --vex-file ./vex.cdx.json
+
+# Target the project by UUID:
+trivy dependencytrack vex upload --project-uuid 3ac5a1c2-0000-0000-0000-000000000000 --vex-file ./vex.cdx.json
+`,
+	}
+
+	addServerFlags(cmd)
+	addProjectFlags(cmd)
+
+	cmd.Flags().String(common.VVexFile, common.VVexFileDefault, common.VVexFileUsage)
+	err := viper.BindPFlag(common.VVexFile, cmd.Flags().Lookup(common.VVexFileLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	return cmd
+}
+
+func uploadVex(server config.Server, projectUUID string, projectName string, projectVersion string, vexFile string) error {
+	vexContent, err := os.ReadFile(vexFile)
+	if err != nil {
+		return exit.Config(fmt.Errorf("failed to read vex file: %w", err))
+	}
+	doc, err := vex.ParseCycloneDX(vexContent)
+	if err != nil {
+		return exit.Config(fmt.Errorf("invalid vex document %s:\n%w", vexFile, err))
+	}
+	logger.Default().Debug("VEX document validated", "file", vexFile, "vulnerabilities", len(doc.Vulnerabilities))
+
+	ctx := context.TODO()
+	client, err := newClient(server)
+	if err != nil {
+		return err
+	}
+
+	// Resolve the project first: DependencyTrack never auto-creates a project for a VEX document.
+	project, err := resolveProject(ctx, client, projectUUID, projectName, projectVersion)
+	if err != nil {
+		return err
+	}
+
+	token, err := client.VEX.Upload(ctx, dtrack.VEXUploadRequest{
+		ProjectUUID: &project.UUID,
+		VEX:         base64.StdEncoding.EncodeToString(vexContent),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload vex document: %w", err)
+	}
+
+	err = waitForEvent(client, dtrack.EventToken(token), processingTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to wait for vex processing: %w", err)
+	}
+	logger.Default().Info("VEX processing completed", "project", project.Name, "version", project.Version,
+		"vulnerabilities", len(doc.Vulnerabilities))
+	return nil
+}
+
+func NewVexExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [flags]",
+		Short: "Export the DependencyTrack analysis decisions of a project as VEX for trivy --vex",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := loadServerConfig()
+			if err != nil {
+				logger.Default().Error("Error validating server config", "error", err)
+				return err
+			}
+			ProjectUUID := viper.GetString(common.VProjectUUID)
+			ProjectName := viper.GetString(common.VProjectName)
+			ProjectVersion := viper.GetString(common.VProjectVersion)
+			VexFormat := viper.GetString(common.VVexFormat)
+			VexAuthor := viper.GetString(common.VVexAuthor)
+			BomFile := viper.GetString(common.VBomFile)
+			OutputFile := viper.GetString(common.VOutputFile)
+			if VexFormat != "openvex" && VexFormat != "cyclonedx" {
+				err := fmt.Errorf("unsupported vex format: %s", VexFormat)
+				logger.Default().Error("Error validating fields", "error", err)
+				return exit.Config(err)
+			}
+			if VexFormat == "cyclonedx" && BomFile == "" {
+				// trivy only applies CycloneDX VEX statements whose BOM-Links point into the scanned sbom.
+				err := fmt.Errorf("bom-file is required with the cyclonedx vex format, to reference its components")
+				logger.Default().Error("Error validating fields", "error", err)
+				return exit.Config(err)
+			}
+			err = exportVex(server, ProjectUUID, ProjectName, ProjectVersion, VexFormat, VexAuthor, BomFile, OutputFile)
+			if err != nil {
+				logger.Default().Error("Error exporting vex document", "error", err)
+				return err
+			}
+			return nil
+		},
+		Example: `
+# Make local scans respect the triage done in DependencyTrack:
+trivy dependencytrack vex export --project-name my-project --project-version main --output-file ./dt.openvex.json
+trivy image --vex ./dt.openvex.json my-image:latest
+
+# CycloneDX VEX, which needs the scanned sbom to reference its components with BOM-Links:
+trivy dependencytrack vex export --project-name my-project --project-version main --vex-format cyclonedx \
+  --bom-file ./sbom.cdx.json --output-file ./dt.vex.cdx.json
+trivy sbom --vex ./dt.vex.cdx.json ./sbom.cdx.json
+`,
+	}
+
+	addServerFlags(cmd)
+	addProjectFlags(cmd)
+
+	cmd.Flags().String(common.VVexFormat, common.VVexFormatDefault, common.VVexFormatUsage)
+	err := viper.BindPFlag(common.VVexFormat, cmd.Flags().Lookup(common.VVexFormatLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VVexAuthor, common.VVexAuthorDefault, common.VVexAuthorUsage)
+	err = viper.BindPFlag(common.VVexAuthor, cmd.Flags().Lookup(common.VVexAuthorLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VBomFile, common.VBomFileDefault, common.VBomFileUsage)
+	err = viper.BindPFlag(common.VBomFile, cmd.Flags().Lookup(common.VBomFileLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().String(common.VOutputFile, common.VOutputFileDefault, common.VOutputFileUsage)
+	err = viper.BindPFlag(common.VOutputFile, cmd.Flags().Lookup(common.VOutputFileLong))
+	if err != nil {
+		logger.Default().Error("Error binding flag to viper", "error", err)
+		os.Exit(1)
+	}
+
+	return cmd
+}
+
+func exportVex(server config.Server, projectUUID string, projectName string, projectVersion string, vexFormat string, vexAuthor string, bomFile string, outputFile string) error {
+	ctx := context.TODO()
+	client, err := newClient(server)
+	if err != nil {
+		return err
+	}
+	project, err := resolveProject(ctx, client, projectUUID, projectName, projectVersion)
+	if err != nil {
+		return err
+	}
+	decisions, err := vex.FetchDecisions(ctx, client, project.UUID)
+	if err != nil {
+		return err
+	}
+
+	var doc any
+	var skipped []vex.Decision
+	switch vexFormat {
+	case "cyclonedx":
+		var bom struct {
+			SerialNumber string `json:"serialNumber"`
+			Version      int    `json:"version"`
+		}
+		bomContent, err := os.ReadFile(bomFile)
+		if err != nil {
+			return fmt.Errorf("failed to read bom file: %w", err)
+		}
+		err = json.Unmarshal(bomContent, &bom)
+		if err != nil {
+			return fmt.Errorf("failed to decode bom file: %w", err)
+		}
+		if bom.SerialNumber == "" {
+			return exit.Config(fmt.Errorf("bom file %s has no serialNumber to reference", bomFile))
+		}
+		if bom.Version == 0 {
+			bom.Version = 1
+		}
+		product := vex.Component{Type: "application", Name: project.Name, Version: project.Version}
+		doc, skipped = vex.ToCycloneDX(product, bom.SerialNumber, bom.Version, decisions)
+	default:
+		doc, skipped = vex.ToOpenVEX(vexAuthor, decisions)
+	}
+	for _, d := range skipped {
+		logger.Default().Warn("Skipping analysis of a component without purl", "vulnerability", d.VulnID,
+			"component", d.ComponentName, "version", d.ComponentVersion)
+	}
+
+	out := os.Stdout
+	if outputFile != "" {
+		out, err = os.Create(outputFile)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(doc)
+	if out != os.Stdout {
+		// A failed close can lose the end of the written document.
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write vex document: %w", err)
+	}
+	logger.Default().Info("VEX document exported", "project", project.Name, "version", project.Version,
+		"format", vexFormat, "statements", len(decisions)-len(skipped))
+	return nil
+}
@@ -2,6 +2,9 @@ package main
 
 import "github.com/weeros/trivy-plugin-dependencytrack/cmd"
 
+// version is set at build time by goreleaser.
+var version = "dev"
+
 func main() {
-	cmd.Execute()
+	cmd.Execute(version)
 }
\ No newline at end of file
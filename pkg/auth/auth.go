@@ -0,0 +1,136 @@
+// Package auth authenticates the requests to DependencyTrack with a bearer token, obtained by logging in or read from
+// a file, and renews the token when the server rejects it.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/logger"
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/secret"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+// TokenSource returns a bearer token. It is called again when the server rejects the previous one.
+type TokenSource func(ctx context.Context) (string, error)
+
+// Static always returns the same token, which cannot be renewed.
+func Static(token string) TokenSource {
+	return func(ctx context.Context) (string, error) {
+		return token, nil
+	}
+}
+
+// File reads the token from a file on each call, so that a token rotated by a sidecar or a secret store is picked up.
+func File(path string) TokenSource {
+	return func(ctx context.Context) (string, error) {
+		token, err := secret.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		logger.RedactSecret(token)
+		return token, nil
+	}
+}
+
+// Login logs in to DependencyTrack with a username and password to get a new token, e.g. for a service account of an
+// instance behind SSO.
+func Login(baseURL string, username string, password string, options ...dtrack.ClientOption) TokenSource {
+	return func(ctx context.Context) (string, error) {
+		client, err := dtrack.NewClient(baseURL, options...)
+		if err != nil {
+			return "", fmt.Errorf("failed to create dependencytrack client: %w", err)
+		}
+		token, err := client.User.Login(ctx, username, password)
+		if err != nil {
+			return "", fmt.Errorf("failed to login to dependencytrack as %s: %w", username, err)
+		}
+		logger.RedactSecret(token)
+		return token, nil
+	}
+}
+
+// Transport sets the bearer token on the requests. When the server answers 401, it gets a new token from its source
+// and retries the request once.
+type Transport struct {
+	base   http.RoundTripper
+	source TokenSource
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewTransport gets a first token, so that invalid credentials are reported before any request. A nil base uses
+// http.DefaultTransport.
+func NewTransport(ctx context.Context, base http.RoundTripper, source TokenSource) (*Transport, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	token, err := source(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, fmt.Errorf("empty bearer token")
+	}
+	return &Transport{base: base, source: source, token: token}, nil
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token := t.current()
+	resp, err := t.base.RoundTrip(withToken(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// A consumed body cannot be sent again, the caller gets the 401.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+	renewed, err := t.renew(req.Context(), token)
+	if err != nil || renewed == token {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	retry := withToken(req, renewed)
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(retry)
+}
+
+func (t *Transport) current() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.token
+}
+
+// renew replaces the rejected token, unless a concurrent request already did.
+func (t *Transport) renew(ctx context.Context, rejected string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token != rejected {
+		return t.token, nil
+	}
+	token, err := t.source(ctx)
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		t.token = token
+	}
+	return t.token, nil
+}
+
+func withToken(req *http.Request, token string) *http.Request {
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Authorization", "Bearer "+token)
+	return clone
+}
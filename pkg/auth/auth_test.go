@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeServer accepts the requests carrying the bearer token in valid, and records every request it receives.
+type fakeServer struct {
+	mu       sync.Mutex
+	valid    string
+	requests []fakeRequest
+}
+
+type fakeRequest struct {
+	authorization string
+	body          string
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, fakeRequest{authorization: r.Header.Get("Authorization"), body: string(body)})
+	if r.Header.Get("Authorization") != "Bearer "+s.valid {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	fmt.Fprint(w, "ok")
+}
+
+// counting returns the tokens in order, repeating the last one, and counts the calls.
+func counting(calls *int, tokens ...string) TokenSource {
+	return func(ctx context.Context) (string, error) {
+		*calls++
+		return tokens[min(*calls, len(tokens))-1], nil
+	}
+}
+
+func TestTransport(t *testing.T) {
+	tests := []struct {
+		name         string
+		valid        string
+		tokens       []string
+		body         func() io.Reader
+		wantStatus   int
+		wantRequests []fakeRequest
+		wantSources  int
+	}{
+		{
+			name:       "valid token",
+			valid:      "first",
+			tokens:     []string{"first"},
+			body:       func() io.Reader { return strings.NewReader(`{"name":"app"}`) },
+			wantStatus: http.StatusOK,
+			wantRequests: []fakeRequest{
+				{authorization: "Bearer first", body: `{"name":"app"}`},
+			},
+			wantSources: 1,
+		},
+		{
+			name:       "401 renews the token once and replays the body",
+			valid:      "second",
+			tokens:     []string{"first", "second"},
+			body:       func() io.Reader { return strings.NewReader(`{"name":"app"}`) },
+			wantStatus: http.StatusOK,
+			wantRequests: []fakeRequest{
+				{authorization: "Bearer first", body: `{"name":"app"}`},
+				{authorization: "Bearer second", body: `{"name":"app"}`},
+			},
+			wantSources: 2,
+		},
+		{
+			name:       "second 401 returned as is",
+			valid:      "never",
+			tokens:     []string{"first", "second", "third"},
+			body:       func() io.Reader { return strings.NewReader(`{"name":"app"}`) },
+			wantStatus: http.StatusUnauthorized,
+			wantRequests: []fakeRequest{
+				{authorization: "Bearer first", body: `{"name":"app"}`},
+				{authorization: "Bearer second", body: `{"name":"app"}`},
+			},
+			wantSources: 2,
+		},
+		{
+			name:       "source returning the same token does not retry",
+			valid:      "never",
+			tokens:     []string{"static"},
+			wantStatus: http.StatusUnauthorized,
+			wantRequests: []fakeRequest{
+				{authorization: "Bearer static"},
+			},
+			wantSources: 2,
+		},
+		{
+			name:   "body that cannot be replayed is not retried",
+			valid:  "second",
+			tokens: []string{"first", "second"},
+			// Hiding the reader type keeps http.NewRequest from setting GetBody.
+			body:       func() io.Reader { return io.MultiReader(strings.NewReader(`{"name":"app"}`)) },
+			wantStatus: http.StatusUnauthorized,
+			wantRequests: []fakeRequest{
+				{authorization: "Bearer first", body: `{"name":"app"}`},
+			},
+			wantSources: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeServer{valid: tt.valid}
+			server := httptest.NewServer(fake)
+			defer server.Close()
+
+			calls := 0
+			transport, err := NewTransport(context.Background(), nil, counting(&calls, tt.tokens...))
+			if err != nil {
+				t.Fatalf("NewTransport() error = %v", err)
+			}
+
+			var body io.Reader
+			method := http.MethodGet
+			if tt.body != nil {
+				body, method = tt.body(), http.MethodPut
+			}
+			req, err := http.NewRequest(method, server.URL+"/api/v1/bom", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := (&http.Client{Transport: transport}).Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if calls != tt.wantSources {
+				t.Errorf("token source called %d times, want %d", calls, tt.wantSources)
+			}
+			if fmt.Sprint(fake.requests) != fmt.Sprint(tt.wantRequests) {
+				t.Errorf("server received %+v, want %+v", fake.requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestNewTransportEmptyToken(t *testing.T) {
+	if _, err := NewTransport(context.Background(), nil, Static("")); err == nil {
+		t.Fatal("NewTransport() error = nil, want an error for an empty token")
+	}
+}
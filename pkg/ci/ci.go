@@ -0,0 +1,115 @@
+// Package ci detects the CI system running the plugin and reads its build context.
+package ci
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RefType is the kind of ref a pipeline runs for.
+type RefType string
+
+const (
+	RefBranch       RefType = "branch"
+	RefTag          RefType = "tag"
+	RefMergeRequest RefType = "merge_request"
+)
+
+// Context is the build context read from the CI variables. Fields the CI does not expose are empty.
+type Context struct {
+	Provider    string
+	ProjectName string
+	// Namespace is the group, organization or workspace owning the repository, Repository its short name.
+	Namespace  string
+	Repository string
+	// Version is the branch or tag name, the source branch for merge requests.
+	Version string
+	RefType RefType
+	// MergeRequestID and TargetBranch are only set for merge requests.
+	MergeRequestID string
+	TargetBranch   string
+	DefaultBranch  string
+	CommitSHA      string
+	BuildURL       string
+}
+
+// Baseline is the branch to compare findings with: the target branch of a merge request, the default branch otherwise.
+func (c Context) Baseline() string {
+	if c.RefType == RefMergeRequest && c.TargetBranch != "" {
+		return c.TargetBranch
+	}
+	return c.DefaultBranch
+}
+
+// Getenv reads an environment variable, os.Getenv in production.
+type Getenv func(key string) string
+
+// Provider reads the build context of one CI system.
+type Provider interface {
+	Name() string
+	// Detect reports whether the plugin runs in this CI.
+	Detect(getenv Getenv) bool
+	Context(getenv Getenv) Context
+}
+
+// Providers are tried in order by Detect. Jenkins comes last as its variables are easily inherited by other tools.
+var Providers = []Provider{GitLab{}, GitHub{}, Azure{}, Bitbucket{}, Jenkins{}}
+
+// Auto selects the provider by detection in Lookup.
+const Auto = "auto"
+
+// Detect returns the first provider detecting its CI.
+func Detect(getenv Getenv) (Provider, bool) {
+	for _, p := range Providers {
+		if p.Detect(getenv) {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Lookup returns the provider with the given name, or the detected one for Auto.
+func Lookup(name string, getenv Getenv) (Provider, error) {
+	if name == Auto {
+		p, ok := Detect(getenv)
+		if !ok {
+			return nil, fmt.Errorf("no supported CI detected, expected one of %v", Names())
+		}
+		return p, nil
+	}
+	i := slices.IndexFunc(Providers, func(p Provider) bool { return p.Name() == strings.ToLower(name) })
+	if i < 0 {
+		return nil, fmt.Errorf("unsupported CI %q, expected %s or one of %v", name, Auto, Names())
+	}
+	return Providers[i], nil
+}
+
+// Names lists the provider names.
+func Names() []string {
+	names := make([]string, 0, len(Providers))
+	for _, p := range Providers {
+		names = append(names, p.Name())
+	}
+	return names
+}
+
+// firstOf returns the first non-empty variable.
+func firstOf(getenv Getenv, keys ...string) string {
+	for _, k := range keys {
+		if v := getenv(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// trimRef removes the refs/heads/, refs/tags/ or refs/pull/ prefix of a git ref.
+func trimRef(ref string) string {
+	for _, prefix := range []string{"refs/heads/", "refs/tags/", "refs/pull/"} {
+		if s, ok := strings.CutPrefix(ref, prefix); ok {
+			return s
+		}
+	}
+	return ref
+}
@@ -0,0 +1,67 @@
+package ci
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Filter decides which CI contexts are uploaded. Include and exclude expressions are unanchored regular expressions
+// matched against the branch or tag name; an empty expression is not checked.
+type Filter struct {
+	Branches      bool
+	Tags          bool
+	MergeRequests bool
+
+	BranchInclude string
+	BranchExclude string
+	TagInclude    string
+	TagExclude    string
+}
+
+// Validate compiles every expression and reports all invalid ones at once.
+func (f Filter) Validate() error {
+	var errs []error
+	for _, e := range []struct{ name, expr string }{
+		{"branch include", f.BranchInclude},
+		{"branch exclude", f.BranchExclude},
+		{"tag include", f.TagInclude},
+		{"tag exclude", f.TagExclude},
+	} {
+		if _, err := regexp.Compile(e.expr); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s expression %q: %w", e.name, e.expr, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Skip returns why the context is filtered out, or an empty string when it is allowed. The filter must be valid.
+func (f Filter) Skip(c Context) string {
+	switch c.RefType {
+	case RefMergeRequest:
+		if !f.MergeRequests {
+			return "merge request pipelines are not allowed"
+		}
+	case RefBranch:
+		if !f.Branches {
+			return "branch pipelines are not allowed"
+		}
+		return skipRef("branch", c.Version, f.BranchInclude, f.BranchExclude)
+	case RefTag:
+		if !f.Tags {
+			return "tag pipelines are not allowed"
+		}
+		return skipRef("tag", c.Version, f.TagInclude, f.TagExclude)
+	}
+	return ""
+}
+
+func skipRef(kind string, name string, include string, exclude string) string {
+	if include != "" && !regexp.MustCompile(include).MatchString(name) {
+		return fmt.Sprintf("%s %q does not match %q", kind, name, include)
+	}
+	if exclude != "" && regexp.MustCompile(exclude).MatchString(name) {
+		return fmt.Sprintf("%s %q matches excluded %q", kind, name, exclude)
+	}
+	return ""
+}
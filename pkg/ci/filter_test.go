@@ -0,0 +1,126 @@
+package ci
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilterSkip(t *testing.T) {
+	all := Filter{Branches: true, Tags: true, MergeRequests: true}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		ctx    Context
+		want   string
+	}{
+		{
+			name:   "branch allowed without expressions",
+			filter: all,
+			ctx:    Context{RefType: RefBranch, Version: "feature/x"},
+		},
+		{
+			name:   "branches disabled",
+			filter: Filter{Tags: true},
+			ctx:    Context{RefType: RefBranch, Version: "main"},
+			want:   "branch pipelines are not allowed",
+		},
+		{
+			name:   "tags disabled",
+			filter: Filter{Branches: true},
+			ctx:    Context{RefType: RefTag, Version: "v1.0.0"},
+			want:   "tag pipelines are not allowed",
+		},
+		{
+			name:   "merge requests disabled",
+			filter: Filter{Branches: true, Tags: true},
+			ctx:    Context{RefType: RefMergeRequest, Version: "feature/x"},
+			want:   "merge request pipelines are not allowed",
+		},
+		{
+			name:   "merge requests ignore branch expressions",
+			filter: Filter{MergeRequests: true, BranchInclude: "^main$"},
+			ctx:    Context{RefType: RefMergeRequest, Version: "feature/x"},
+		},
+		{
+			name:   "branch include matches",
+			filter: Filter{Branches: true, BranchInclude: "^(main|release/.+)$"},
+			ctx:    Context{RefType: RefBranch, Version: "release/1.x"},
+		},
+		{
+			name:   "branch include does not match",
+			filter: Filter{Branches: true, BranchInclude: "^(main|release/.+)$"},
+			ctx:    Context{RefType: RefBranch, Version: "feature/x"},
+			want:   `branch "feature/x" does not match "^(main|release/.+)$"`,
+		},
+		{
+			name:   "branch include is unanchored",
+			filter: Filter{Branches: true, BranchInclude: "release"},
+			ctx:    Context{RefType: RefBranch, Version: "hotfix/release-notes"},
+		},
+		{
+			name:   "branch exclude matches",
+			filter: Filter{Branches: true, BranchExclude: "^dependabot/"},
+			ctx:    Context{RefType: RefBranch, Version: "dependabot/npm/lodash"},
+			want:   `branch "dependabot/npm/lodash" matches excluded "^dependabot/"`,
+		},
+		{
+			name:   "exclude wins over include",
+			filter: Filter{Branches: true, BranchInclude: "^release/", BranchExclude: "-rc$"},
+			ctx:    Context{RefType: RefBranch, Version: "release/2.0-rc"},
+			want:   `branch "release/2.0-rc" matches excluded "-rc$"`,
+		},
+		{
+			name:   "include reported before exclude",
+			filter: Filter{Branches: true, BranchInclude: "^main$", BranchExclude: "feature"},
+			ctx:    Context{RefType: RefBranch, Version: "feature/x"},
+			want:   `branch "feature/x" does not match "^main$"`,
+		},
+		{
+			name:   "tag include matches",
+			filter: Filter{Tags: true, TagInclude: `^v?[0-9]+\.[0-9]+\.[0-9]+$`},
+			ctx:    Context{RefType: RefTag, Version: "v1.2.3"},
+		},
+		{
+			name:   "tag excluded",
+			filter: Filter{Tags: true, TagInclude: `^v`, TagExclude: `-(alpha|beta)`},
+			ctx:    Context{RefType: RefTag, Version: "v1.2.3-beta.1"},
+			want:   `tag "v1.2.3-beta.1" matches excluded "-(alpha|beta)"`,
+		},
+		{
+			name:   "tag expressions do not apply to branches",
+			filter: Filter{Branches: true, Tags: true, TagInclude: `^v`},
+			ctx:    Context{RefType: RefBranch, Version: "main"},
+		},
+		{
+			name:   "unknown ref type allowed",
+			filter: Filter{},
+			ctx:    Context{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if got := tt.filter.Skip(tt.ctx); got != tt.want {
+				t.Errorf("Skip() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterValidate(t *testing.T) {
+	err := Filter{BranchInclude: "^(main", BranchExclude: "ok", TagExclude: "[v"}.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil, want the invalid expressions")
+	}
+	for _, want := range []string{`invalid branch include expression "^(main"`, `invalid tag exclude expression "[v"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %q, want it to contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "branch exclude") {
+		t.Errorf("Validate() error = %q, reports a valid expression", err)
+	}
+}
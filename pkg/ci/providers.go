@@ -0,0 +1,207 @@
+package ci
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// GitLab reads the GitLab CI predefined variables.
+type GitLab struct{}
+
+func (GitLab) Name() string { return "gitlab" }
+
+func (GitLab) Detect(getenv Getenv) bool { return getenv("GITLAB_CI") == "true" }
+
+func (p GitLab) Context(getenv Getenv) Context {
+	c := Context{
+		Provider:       p.Name(),
+		ProjectName:    firstOf(getenv, "CI_PROJECT_TITLE", "CI_PROJECT_NAME"),
+		Namespace:      getenv("CI_PROJECT_NAMESPACE"),
+		Repository:     getenv("CI_PROJECT_NAME"),
+		Version:        getenv("CI_COMMIT_REF_NAME"),
+		MergeRequestID: getenv("CI_MERGE_REQUEST_IID"),
+		TargetBranch:   getenv("CI_MERGE_REQUEST_TARGET_BRANCH_NAME"),
+		DefaultBranch:  getenv("CI_DEFAULT_BRANCH"),
+		CommitSHA:      getenv("CI_COMMIT_SHA"),
+		BuildURL:       firstOf(getenv, "CI_JOB_URL", "CI_PIPELINE_URL"),
+	}
+	switch {
+	case c.MergeRequestID != "":
+		c.RefType = RefMergeRequest
+		c.Version = firstOf(getenv, "CI_MERGE_REQUEST_SOURCE_BRANCH_NAME", "CI_COMMIT_REF_NAME")
+	case getenv("CI_COMMIT_TAG") != "":
+		c.RefType = RefTag
+	case getenv("CI_COMMIT_BRANCH") != "":
+		c.RefType = RefBranch
+	}
+	return c
+}
+
+// GitHub reads the GitHub Actions default variables. The default branch comes from the event payload.
+type GitHub struct{}
+
+func (GitHub) Name() string { return "github" }
+
+func (GitHub) Detect(getenv Getenv) bool { return getenv("GITHUB_ACTIONS") == "true" }
+
+func (p GitHub) Context(getenv Getenv) Context {
+	c := Context{
+		Provider:      p.Name(),
+		ProjectName:   getenv("GITHUB_REPOSITORY"),
+		Version:       firstOf(getenv, "GITHUB_HEAD_REF", "GITHUB_REF_NAME"),
+		TargetBranch:  getenv("GITHUB_BASE_REF"),
+		DefaultBranch: githubDefaultBranch(getenv("GITHUB_EVENT_PATH")),
+		CommitSHA:     getenv("GITHUB_SHA"),
+	}
+	c.Namespace, c.Repository, _ = strings.Cut(c.ProjectName, "/")
+	if server, repo, run := getenv("GITHUB_SERVER_URL"), getenv("GITHUB_REPOSITORY"), getenv("GITHUB_RUN_ID"); server != "" && repo != "" && run != "" {
+		c.BuildURL = server + "/" + repo + "/actions/runs/" + run
+	}
+	switch {
+	case strings.HasPrefix(getenv("GITHUB_EVENT_NAME"), "pull_request"):
+		c.RefType = RefMergeRequest
+		c.MergeRequestID, _, _ = strings.Cut(trimRef(getenv("GITHUB_REF")), "/")
+	case getenv("GITHUB_REF_TYPE") == "tag":
+		c.RefType = RefTag
+	case getenv("GITHUB_REF_TYPE") == "branch":
+		c.RefType = RefBranch
+	}
+	return c
+}
+
+func githubDefaultBranch(eventPath string) string {
+	if eventPath == "" {
+		return ""
+	}
+	content, err := os.ReadFile(eventPath)
+	if err != nil {
+		return ""
+	}
+	var event struct {
+		Repository struct {
+			DefaultBranch string `json:"default_branch"`
+		} `json:"repository"`
+	}
+	if json.Unmarshal(content, &event) != nil {
+		return ""
+	}
+	return event.Repository.DefaultBranch
+}
+
+// Jenkins reads the Jenkins environment, including the multibranch and Git plugin variables.
+type Jenkins struct{}
+
+func (Jenkins) Name() string { return "jenkins" }
+
+func (Jenkins) Detect(getenv Getenv) bool { return getenv("JENKINS_URL") != "" }
+
+func (p Jenkins) Context(getenv Getenv) Context {
+	c := Context{
+		Provider:       p.Name(),
+		ProjectName:    getenv("JOB_NAME"),
+		MergeRequestID: getenv("CHANGE_ID"),
+		TargetBranch:   getenv("CHANGE_TARGET"),
+		CommitSHA:      getenv("GIT_COMMIT"),
+		BuildURL:       getenv("BUILD_URL"),
+	}
+	// Multibranch jobs are named after the repository and the branch, e.g. "my-repo/main".
+	if branch := getenv("BRANCH_NAME"); branch != "" {
+		c.ProjectName = strings.TrimSuffix(c.ProjectName, "/"+strings.ReplaceAll(branch, "/", "%2F"))
+	}
+	if c.ProjectName != "" {
+		c.Namespace, c.Repository = splitLast(c.ProjectName)
+	}
+	switch {
+	case c.MergeRequestID != "":
+		c.RefType = RefMergeRequest
+		c.Version = firstOf(getenv, "CHANGE_BRANCH", "BRANCH_NAME")
+	case getenv("TAG_NAME") != "":
+		c.RefType = RefTag
+		c.Version = getenv("TAG_NAME")
+	default:
+		c.Version = firstOf(getenv, "BRANCH_NAME", "GIT_LOCAL_BRANCH")
+		if c.Version == "" {
+			c.Version = strings.TrimPrefix(getenv("GIT_BRANCH"), "origin/")
+		}
+		if c.Version != "" {
+			c.RefType = RefBranch
+		}
+	}
+	return c
+}
+
+// Azure reads the Azure Pipelines predefined variables.
+type Azure struct{}
+
+func (Azure) Name() string { return "azure" }
+
+func (Azure) Detect(getenv Getenv) bool { return strings.EqualFold(getenv("TF_BUILD"), "true") }
+
+func (p Azure) Context(getenv Getenv) Context {
+	c := Context{
+		Provider:    p.Name(),
+		ProjectName: getenv("BUILD_REPOSITORY_NAME"),
+		Namespace:   getenv("SYSTEM_TEAMPROJECT"),
+		Version:     trimRef(getenv("BUILD_SOURCEBRANCH")),
+		CommitSHA:   getenv("BUILD_SOURCEVERSION"),
+	}
+	// GitHub repositories built by Azure Pipelines are named owner/repo.
+	_, c.Repository = splitLast(c.ProjectName)
+	if collection, project, build := getenv("SYSTEM_COLLECTIONURI"), getenv("SYSTEM_TEAMPROJECT"), getenv("BUILD_BUILDID"); collection != "" && project != "" && build != "" {
+		c.BuildURL = strings.TrimSuffix(collection, "/") + "/" + project + "/_build/results?buildId=" + build
+	}
+	switch {
+	case getenv("BUILD_REASON") == "PullRequest":
+		c.RefType = RefMergeRequest
+		c.MergeRequestID = firstOf(getenv, "SYSTEM_PULLREQUEST_PULLREQUESTNUMBER", "SYSTEM_PULLREQUEST_PULLREQUESTID")
+		c.Version = trimRef(getenv("SYSTEM_PULLREQUEST_SOURCEBRANCH"))
+		c.TargetBranch = trimRef(getenv("SYSTEM_PULLREQUEST_TARGETBRANCH"))
+	case strings.HasPrefix(getenv("BUILD_SOURCEBRANCH"), "refs/tags/"):
+		c.RefType = RefTag
+	case strings.HasPrefix(getenv("BUILD_SOURCEBRANCH"), "refs/heads/"):
+		c.RefType = RefBranch
+	}
+	return c
+}
+
+// Bitbucket reads the Bitbucket Pipelines default variables.
+type Bitbucket struct{}
+
+func (Bitbucket) Name() string { return "bitbucket" }
+
+func (Bitbucket) Detect(getenv Getenv) bool { return getenv("BITBUCKET_BUILD_NUMBER") != "" }
+
+func (p Bitbucket) Context(getenv Getenv) Context {
+	c := Context{
+		Provider:       p.Name(),
+		ProjectName:    getenv("BITBUCKET_REPO_SLUG"),
+		Namespace:      getenv("BITBUCKET_WORKSPACE"),
+		Repository:     getenv("BITBUCKET_REPO_SLUG"),
+		Version:        firstOf(getenv, "BITBUCKET_TAG", "BITBUCKET_BRANCH"),
+		MergeRequestID: getenv("BITBUCKET_PR_ID"),
+		TargetBranch:   getenv("BITBUCKET_PR_DESTINATION_BRANCH"),
+		CommitSHA:      getenv("BITBUCKET_COMMIT"),
+	}
+	if origin, build := getenv("BITBUCKET_GIT_HTTP_ORIGIN"), getenv("BITBUCKET_BUILD_NUMBER"); origin != "" && build != "" {
+		c.BuildURL = origin + "/pipelines/results/" + build
+	}
+	switch {
+	case c.MergeRequestID != "":
+		c.RefType = RefMergeRequest
+	case getenv("BITBUCKET_TAG") != "":
+		c.RefType = RefTag
+	case getenv("BITBUCKET_BRANCH") != "":
+		c.RefType = RefBranch
+	}
+	return c
+}
+
+// splitLast splits a path at its last "/", the first part being empty when there is none.
+func splitLast(s string) (string, string) {
+	i := strings.LastIndex(s, "/")
+	if i < 0 {
+		return "", s
+	}
+	return s[:i], s[i+1:]
+}
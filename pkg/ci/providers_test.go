@@ -0,0 +1,276 @@
+package ci
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func fixture(env map[string]string) Getenv {
+	return func(key string) string { return env[key] }
+}
+
+func TestProviders(t *testing.T) {
+	eventPath := filepath.Join(t.TempDir(), "event.json")
+	if err := os.WriteFile(eventPath, []byte(`{"repository":{"default_branch":"main"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		env          map[string]string
+		wantProvider string
+		want         Context
+		wantBaseline string
+	}{
+		{
+			name: "gitlab branch",
+			env: map[string]string{
+				"GITLAB_CI": "true", "CI_PROJECT_TITLE": "My App", "CI_PROJECT_NAME": "my-app", "CI_PROJECT_NAMESPACE": "group/sub",
+				"CI_COMMIT_REF_NAME": "feature/x", "CI_COMMIT_BRANCH": "feature/x", "CI_DEFAULT_BRANCH": "main",
+				"CI_COMMIT_SHA": "abc123", "CI_JOB_URL": "https://gitlab.example.com/group/sub/my-app/-/jobs/1",
+			},
+			wantProvider: "gitlab",
+			want: Context{
+				Provider: "gitlab", ProjectName: "My App", Namespace: "group/sub", Repository: "my-app", Version: "feature/x",
+				RefType: RefBranch, DefaultBranch: "main", CommitSHA: "abc123", BuildURL: "https://gitlab.example.com/group/sub/my-app/-/jobs/1",
+			},
+			wantBaseline: "main",
+		},
+		{
+			name: "gitlab tag",
+			env: map[string]string{
+				"GITLAB_CI": "true", "CI_PROJECT_NAME": "my-app", "CI_COMMIT_REF_NAME": "v1.2.3", "CI_COMMIT_TAG": "v1.2.3",
+				"CI_DEFAULT_BRANCH": "main", "CI_PIPELINE_URL": "https://gitlab.example.com/pipelines/2",
+			},
+			wantProvider: "gitlab",
+			want: Context{
+				Provider: "gitlab", ProjectName: "my-app", Repository: "my-app", Version: "v1.2.3", RefType: RefTag,
+				DefaultBranch: "main", BuildURL: "https://gitlab.example.com/pipelines/2",
+			},
+			wantBaseline: "main",
+		},
+		{
+			name: "gitlab merge request",
+			env: map[string]string{
+				"GITLAB_CI": "true", "CI_PROJECT_NAME": "my-app", "CI_COMMIT_REF_NAME": "refs/merge-requests/7/head",
+				"CI_MERGE_REQUEST_IID": "7", "CI_MERGE_REQUEST_SOURCE_BRANCH_NAME": "feature/x",
+				"CI_MERGE_REQUEST_TARGET_BRANCH_NAME": "develop", "CI_DEFAULT_BRANCH": "main",
+			},
+			wantProvider: "gitlab",
+			want: Context{
+				Provider: "gitlab", ProjectName: "my-app", Repository: "my-app", Version: "feature/x", RefType: RefMergeRequest,
+				MergeRequestID: "7", TargetBranch: "develop", DefaultBranch: "main",
+			},
+			wantBaseline: "develop",
+		},
+		{
+			name: "github branch push",
+			env: map[string]string{
+				"GITHUB_ACTIONS": "true", "GITHUB_EVENT_NAME": "push", "GITHUB_REPOSITORY": "acme/app", "GITHUB_REF": "refs/heads/main",
+				"GITHUB_REF_NAME": "main", "GITHUB_REF_TYPE": "branch", "GITHUB_SHA": "def456", "GITHUB_EVENT_PATH": eventPath,
+				"GITHUB_SERVER_URL": "https://github.com", "GITHUB_RUN_ID": "99",
+			},
+			wantProvider: "github",
+			want: Context{
+				Provider: "github", ProjectName: "acme/app", Namespace: "acme", Repository: "app", Version: "main", RefType: RefBranch,
+				DefaultBranch: "main", CommitSHA: "def456", BuildURL: "https://github.com/acme/app/actions/runs/99",
+			},
+			wantBaseline: "main",
+		},
+		{
+			name: "github tag push",
+			env: map[string]string{
+				"GITHUB_ACTIONS": "true", "GITHUB_EVENT_NAME": "push", "GITHUB_REPOSITORY": "acme/app", "GITHUB_REF": "refs/tags/v2.0.0",
+				"GITHUB_REF_NAME": "v2.0.0", "GITHUB_REF_TYPE": "tag", "GITHUB_EVENT_PATH": eventPath,
+			},
+			wantProvider: "github",
+			want: Context{
+				Provider: "github", ProjectName: "acme/app", Namespace: "acme", Repository: "app", Version: "v2.0.0", RefType: RefTag,
+				DefaultBranch: "main",
+			},
+			wantBaseline: "main",
+		},
+		{
+			name: "github pull request",
+			env: map[string]string{
+				"GITHUB_ACTIONS": "true", "GITHUB_EVENT_NAME": "pull_request", "GITHUB_REPOSITORY": "acme/app",
+				"GITHUB_REF": "refs/pull/42/merge", "GITHUB_REF_NAME": "42/merge", "GITHUB_HEAD_REF": "feature/y",
+				"GITHUB_BASE_REF": "release/1.x", "GITHUB_EVENT_PATH": eventPath,
+			},
+			wantProvider: "github",
+			want: Context{
+				Provider: "github", ProjectName: "acme/app", Namespace: "acme", Repository: "app", Version: "feature/y",
+				RefType: RefMergeRequest, MergeRequestID: "42", TargetBranch: "release/1.x", DefaultBranch: "main",
+			},
+			wantBaseline: "release/1.x",
+		},
+		{
+			name: "azure branch",
+			env: map[string]string{
+				"TF_BUILD": "True", "BUILD_REPOSITORY_NAME": "acme/app", "SYSTEM_TEAMPROJECT": "Platform",
+				"BUILD_SOURCEBRANCH": "refs/heads/main", "BUILD_SOURCEVERSION": "aaa111",
+				"SYSTEM_COLLECTIONURI": "https://dev.azure.com/acme/", "BUILD_BUILDID": "5",
+			},
+			wantProvider: "azure",
+			want: Context{
+				Provider: "azure", ProjectName: "acme/app", Namespace: "Platform", Repository: "app", Version: "main", RefType: RefBranch,
+				CommitSHA: "aaa111", BuildURL: "https://dev.azure.com/acme/Platform/_build/results?buildId=5",
+			},
+		},
+		{
+			name: "azure tag",
+			env: map[string]string{
+				"TF_BUILD": "True", "BUILD_REPOSITORY_NAME": "app", "BUILD_SOURCEBRANCH": "refs/tags/v3.1.0",
+			},
+			wantProvider: "azure",
+			want:         Context{Provider: "azure", ProjectName: "app", Repository: "app", Version: "v3.1.0", RefType: RefTag},
+		},
+		{
+			name: "azure pull request",
+			env: map[string]string{
+				"TF_BUILD": "True", "BUILD_REPOSITORY_NAME": "app", "BUILD_REASON": "PullRequest",
+				"BUILD_SOURCEBRANCH": "refs/pull/12/merge", "SYSTEM_PULLREQUEST_PULLREQUESTID": "1200",
+				"SYSTEM_PULLREQUEST_PULLREQUESTNUMBER": "12", "SYSTEM_PULLREQUEST_SOURCEBRANCH": "refs/heads/feature/z",
+				"SYSTEM_PULLREQUEST_TARGETBRANCH": "refs/heads/main",
+			},
+			wantProvider: "azure",
+			want: Context{
+				Provider: "azure", ProjectName: "app", Repository: "app", Version: "feature/z", RefType: RefMergeRequest,
+				MergeRequestID: "12", TargetBranch: "main",
+			},
+			wantBaseline: "main",
+		},
+		{
+			name: "bitbucket branch",
+			env: map[string]string{
+				"BITBUCKET_BUILD_NUMBER": "8", "BITBUCKET_REPO_SLUG": "app", "BITBUCKET_WORKSPACE": "acme",
+				"BITBUCKET_BRANCH": "develop", "BITBUCKET_COMMIT": "bbb222", "BITBUCKET_GIT_HTTP_ORIGIN": "https://bitbucket.org/acme/app",
+			},
+			wantProvider: "bitbucket",
+			want: Context{
+				Provider: "bitbucket", ProjectName: "app", Namespace: "acme", Repository: "app", Version: "develop", RefType: RefBranch,
+				CommitSHA: "bbb222", BuildURL: "https://bitbucket.org/acme/app/pipelines/results/8",
+			},
+		},
+		{
+			name: "bitbucket tag",
+			env: map[string]string{
+				"BITBUCKET_BUILD_NUMBER": "9", "BITBUCKET_REPO_SLUG": "app", "BITBUCKET_TAG": "v1.0.0",
+			},
+			wantProvider: "bitbucket",
+			want:         Context{Provider: "bitbucket", ProjectName: "app", Repository: "app", Version: "v1.0.0", RefType: RefTag},
+		},
+		{
+			name: "bitbucket pull request",
+			env: map[string]string{
+				"BITBUCKET_BUILD_NUMBER": "10", "BITBUCKET_REPO_SLUG": "app", "BITBUCKET_BRANCH": "feature/w",
+				"BITBUCKET_PR_ID": "3", "BITBUCKET_PR_DESTINATION_BRANCH": "main",
+			},
+			wantProvider: "bitbucket",
+			want: Context{
+				Provider: "bitbucket", ProjectName: "app", Repository: "app", Version: "feature/w", RefType: RefMergeRequest,
+				MergeRequestID: "3", TargetBranch: "main",
+			},
+			wantBaseline: "main",
+		},
+		{
+			name: "jenkins multibranch",
+			env: map[string]string{
+				"JENKINS_URL": "https://jenkins.example.com/", "JOB_NAME": "team/app/feature%2Fv", "BRANCH_NAME": "feature/v",
+				"GIT_COMMIT": "ccc333", "BUILD_URL": "https://jenkins.example.com/job/team/job/app/3/",
+			},
+			wantProvider: "jenkins",
+			want: Context{
+				Provider: "jenkins", ProjectName: "team/app", Namespace: "team", Repository: "app", Version: "feature/v",
+				RefType: RefBranch, CommitSHA: "ccc333", BuildURL: "https://jenkins.example.com/job/team/job/app/3/",
+			},
+		},
+		{
+			name: "jenkins git plugin branch",
+			env: map[string]string{
+				"JENKINS_URL": "https://jenkins.example.com/", "JOB_NAME": "app-build", "GIT_BRANCH": "origin/main",
+			},
+			wantProvider: "jenkins",
+			want:         Context{Provider: "jenkins", ProjectName: "app-build", Repository: "app-build", Version: "main", RefType: RefBranch},
+		},
+		{
+			name: "jenkins tag",
+			env: map[string]string{
+				"JENKINS_URL": "https://jenkins.example.com/", "JOB_NAME": "app/v1.0.0", "BRANCH_NAME": "v1.0.0", "TAG_NAME": "v1.0.0",
+			},
+			wantProvider: "jenkins",
+			want:         Context{Provider: "jenkins", ProjectName: "app", Repository: "app", Version: "v1.0.0", RefType: RefTag},
+		},
+		{
+			name: "jenkins change request",
+			env: map[string]string{
+				"JENKINS_URL": "https://jenkins.example.com/", "JOB_NAME": "app/PR-5", "BRANCH_NAME": "PR-5",
+				"CHANGE_ID": "5", "CHANGE_BRANCH": "feature/u", "CHANGE_TARGET": "main",
+			},
+			wantProvider: "jenkins",
+			want: Context{
+				Provider: "jenkins", ProjectName: "app", Repository: "app", Version: "feature/u", RefType: RefMergeRequest,
+				MergeRequestID: "5", TargetBranch: "main",
+			},
+			wantBaseline: "main",
+		},
+		{
+			name:         "gitlab detected before jenkins variables it inherited",
+			env:          map[string]string{"GITLAB_CI": "true", "JENKINS_URL": "https://jenkins.example.com/", "CI_PROJECT_NAME": "app"},
+			wantProvider: "gitlab",
+			want:         Context{Provider: "gitlab", ProjectName: "app", Repository: "app"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := fixture(tt.env)
+			provider, err := Lookup(Auto, getenv)
+			if err != nil {
+				t.Fatalf("Lookup(auto) error = %v", err)
+			}
+			if provider.Name() != tt.wantProvider {
+				t.Fatalf("detected %s, want %s", provider.Name(), tt.wantProvider)
+			}
+
+			got := provider.Context(getenv)
+			if got != tt.want {
+				t.Errorf("Context() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+			if baseline := got.Baseline(); baseline != tt.wantBaseline {
+				t.Errorf("Baseline() = %q, want %q", baseline, tt.wantBaseline)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		env      map[string]string
+		want     string
+		wantErr  bool
+	}{
+		{name: "auto without any CI", provider: Auto, env: map[string]string{"HOME": "/root"}, wantErr: true},
+		{name: "explicit provider without its variables", provider: "GitHub", env: map[string]string{}, want: "github"},
+		{name: "unknown provider", provider: "circleci", env: map[string]string{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Lookup(tt.provider, fixture(tt.env))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Lookup(%q) = %s, want an error", tt.provider, p.Name())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lookup(%q) error = %v", tt.provider, err)
+			}
+			if p.Name() != tt.want {
+				t.Errorf("Lookup(%q) = %s, want %s", tt.provider, p.Name(), tt.want)
+			}
+		})
+	}
+}
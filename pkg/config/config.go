@@ -0,0 +1,118 @@
+// Package config describes the keys of the plugin config file, writes a config template and validates configs.
+package config
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Key types besides the pflag ones (bool, string, duration, stringSlice, stringArray...).
+const (
+	TypeMap  = "map"
+	TypeList = "list"
+)
+
+// Key is a config key, settable from the config file, its environment variable and, for most keys, a flag.
+type Key struct {
+	Name        string
+	Type        string
+	Default     string
+	Description string
+	// Flag is the long name of the flag setting the key, empty for config file only keys.
+	Flag string
+}
+
+var cfgFileRegexp = regexp.MustCompile(`(?m)^CfgFile: (\S+)\n?`)
+
+// KeyFromFlag reads the config key documented by the "CfgFile: <key>" line of a flag usage. Flags without this line,
+// such as --config, are not config keys.
+func KeyFromFlag(f *pflag.Flag) (Key, bool) {
+	m := cfgFileRegexp.FindStringSubmatchIndex(f.Usage)
+	if m == nil {
+		return Key{}, false
+	}
+	var description []string
+	for _, line := range strings.Split(f.Usage[:m[0]]+f.Usage[m[1]:], "\n") {
+		if line != "" && !strings.HasPrefix(line, "Env: ") {
+			description = append(description, line)
+		}
+	}
+	return Key{
+		Name:        f.Usage[m[2]:m[3]],
+		Type:        f.Value.Type(),
+		Default:     f.DefValue,
+		Description: strings.Join(description, "\n"),
+		Flag:        f.Name,
+	}, true
+}
+
+// Registry is the set of known config keys, by name.
+type Registry map[string]Key
+
+// Add registers the config keys of the flags. A key registered by several commands keeps its first description.
+func (r Registry) Add(flags *pflag.FlagSet) {
+	flags.VisitAll(func(f *pflag.Flag) {
+		k, ok := KeyFromFlag(f)
+		if !ok {
+			return
+		}
+		if _, exists := r[k.Name]; !exists {
+			r[k.Name] = k
+		}
+	})
+}
+
+// Sorted returns the keys ordered by name.
+func (r Registry) Sorted() []Key {
+	keys := make([]Key, 0, len(r))
+	for _, k := range r {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b Key) int { return strings.Compare(a.Name, b.Name) })
+	return keys
+}
+
+// EnvName returns the environment variable of a key, as resolved by viper with the "-" and "." to "_" replacer.
+func EnvName(prefix string, key string) string {
+	return prefix + "_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+}
+
+// Suggest returns the known key closest to an unknown one, or "" when none is close enough to be a typo.
+func (r Registry) Suggest(name string) string {
+	best, bestDistance := "", 3
+	for k := range r {
+		if strings.ReplaceAll(k, "-", "") == strings.ReplaceAll(name, "-", "") {
+			return k
+		}
+		if d := levenshtein(strings.ToLower(name), k); d < bestDistance || (d == bestDistance && k < best) {
+			best, bestDistance = k, d
+		}
+	}
+	if bestDistance > 2 {
+		return ""
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,306 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/secret"
+
+	"github.com/google/uuid"
+)
+
+// Config is the typed configuration shared by the commands, decoded from the flags, environment variables and config
+// file merged by viper. Command specific options are read by the commands themselves.
+type Config struct {
+	Server  `mapstructure:",squash"`
+	Project `mapstructure:",squash"`
+	Upload  `mapstructure:",squash"`
+}
+
+// Server holds the settings to reach DependencyTrack.
+type Server struct {
+	URLAPI        string `mapstructure:"url-api"`
+	APIKey        string `mapstructure:"apikey"`
+	APIKeyFile    string `mapstructure:"apikey-file"`
+	APIKeyCommand string `mapstructure:"apikey-command"`
+
+	BearerToken     string `mapstructure:"bearer-token"`
+	BearerTokenFile string `mapstructure:"bearer-token-file"`
+
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+
+	TLS  `mapstructure:",squash"`
+	HTTP `mapstructure:",squash"`
+}
+
+// HTTP holds the settings of the HTTP requests to DependencyTrack, e.g. through an egress proxy or an API gateway.
+type HTTP struct {
+	// Proxy overrides the HTTP(S)_PROXY environment variables, NoProxy the NO_PROXY one.
+	Proxy   string   `mapstructure:"proxy"`
+	NoProxy string   `mapstructure:"no-proxy"`
+	Headers []string `mapstructure:"header"`
+	// SecretHeaders names the headers whose values are secrets, besides the credential-like ones.
+	SecretHeaders []string `mapstructure:"secret-header"`
+	// Timeout bounds each request, the whole upload of a large sbom included.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// Validate reports every problem of the HTTP settings at once.
+func (h HTTP) Validate() error {
+	var errs []error
+	if h.Proxy != "" {
+		u, err := url.Parse(h.Proxy)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid proxy: %w", err))
+		} else if !slices.Contains([]string{"http", "https", "socks5"}, u.Scheme) || u.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid proxy %q, expected http(s)://[user:password@]host:port or socks5://host:port", u.Redacted()))
+		}
+	}
+	for _, header := range h.Headers {
+		if _, _, err := ParseHeader(header); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if h.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("invalid timeout %s, expected a positive duration", h.Timeout))
+	}
+	return errors.Join(errs...)
+}
+
+var headerNameRegexp = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// ParseHeader splits a Key=Value header option.
+func ParseHeader(s string) (name string, value string, err error) {
+	name, value, ok := strings.Cut(s, "=")
+	name = strings.TrimSpace(name)
+	if !ok || !headerNameRegexp.MatchString(name) {
+		return "", "", fmt.Errorf("invalid header %q, expected Key=Value", name)
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return "", "", fmt.Errorf("invalid header %s, its value contains a line break", name)
+	}
+	return name, strings.TrimSpace(value), nil
+}
+
+// SecretHeader reports whether the value of the header is a secret: Authorization, Cookie, *-Token and *-Key headers,
+// or a header listed in SecretHeaders. Other values, e.g. a tenant, are kept in the logs.
+func (h HTTP) SecretHeader(name string) bool {
+	for _, secret := range h.SecretHeaders {
+		if strings.EqualFold(strings.TrimSpace(secret), name) {
+			return true
+		}
+	}
+	name = strings.ToLower(name)
+	return name == "cookie" || strings.HasSuffix(name, "authorization") ||
+		strings.HasSuffix(name, "-token") || strings.HasSuffix(name, "-key")
+}
+
+// TLS holds the settings of the TLS connections to DependencyTrack, e.g. for an instance signed by a corporate CA or
+// requiring client certificates at its ingress.
+type TLS struct {
+	CACert             string `mapstructure:"ca-cert"`
+	ClientCert         string `mapstructure:"client-cert"`
+	ClientKey          string `mapstructure:"client-key"`
+	MinVersion         string `mapstructure:"tls-min-version"`
+	InsecureSkipVerify bool   `mapstructure:"insecure-skip-verify"`
+}
+
+// TLSVersions maps the accepted tls-min-version values to their protocol version.
+var TLSVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Validate reports every problem of the TLS settings at once. The certificates themselves are loaded with the client.
+func (t TLS) Validate() error {
+	var errs []error
+	for _, f := range []struct{ key, path string }{{"ca-cert", t.CACert}, {"client-cert", t.ClientCert}, {"client-key", t.ClientKey}} {
+		if f.path == "" {
+			continue
+		}
+		if _, err := os.Stat(f.path); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s: %w", f.key, err))
+		}
+	}
+	if (t.ClientCert == "") != (t.ClientKey == "") {
+		errs = append(errs, fmt.Errorf("client-cert and client-key must be set together"))
+	}
+	if _, ok := TLSVersions[t.MinVersion]; t.MinVersion != "" && !ok {
+		errs = append(errs, fmt.Errorf("invalid tls-min-version %q, expected one of %v", t.MinVersion, slices.Sorted(maps.Keys(TLSVersions))))
+	}
+	return errors.Join(errs...)
+}
+
+// ResolveAPIKey reads the API key from its file or helper command, the inline key, the file and the command being
+// exclusive.
+func (s *Server) ResolveAPIKey(ctx context.Context) error {
+	sources := 0
+	for _, v := range []string{s.APIKey, s.APIKeyFile, s.APIKeyCommand} {
+		if v != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("apikey, apikey-file and apikey-command are exclusive, set only one")
+	}
+
+	var err error
+	switch {
+	case s.APIKeyFile != "":
+		s.APIKey, err = secret.ReadFile(s.APIKeyFile)
+	case s.APIKeyCommand != "":
+		s.APIKey, err = secret.Command(ctx, s.APIKeyCommand)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read apikey: %w", err)
+	}
+	return nil
+}
+
+// Project selects a project by UUID, or by name and version.
+type Project struct {
+	ProjectUUID    string `mapstructure:"project-uuid"`
+	ProjectName    string `mapstructure:"project-name"`
+	ProjectVersion string `mapstructure:"project-version"`
+}
+
+// Upload holds the settings of the upload commands.
+type Upload struct {
+	BOMFile    string `mapstructure:"bom-file"`
+	AutoCreate bool   `mapstructure:"auto-create"`
+}
+
+// DependencyTrack API keys are at least 32 characters long, with an "odt_" prefix since 4.10.
+var apiKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]{32,}$`)
+
+// Validate reports every problem of the server settings at once.
+func (s Server) Validate() error {
+	var errs []error
+	if s.URLAPI == "" {
+		errs = append(errs, fmt.Errorf("url-api is required"))
+	} else if err := ValidateURL(s.URLAPI); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.TLS.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.HTTP.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.ValidateAuth(); err != nil {
+		errs = append(errs, err)
+	} else if s.APIKey != "" {
+		if err := ValidateAPIKey(s.APIKey); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HasAuth reports whether any auth setting is set.
+func (s Server) HasAuth() bool {
+	for _, v := range []string{s.APIKey, s.APIKeyFile, s.APIKeyCommand, s.BearerToken, s.BearerTokenFile, s.Username, s.Password} {
+		if v != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateAuth checks that exactly one auth method is set: an API key, a bearer token, or a username and password.
+func (s Server) ValidateAuth() error {
+	var methods []string
+	if s.APIKey != "" || s.APIKeyFile != "" || s.APIKeyCommand != "" {
+		methods = append(methods, "apikey")
+	}
+	if s.BearerToken != "" || s.BearerTokenFile != "" {
+		methods = append(methods, "bearer-token")
+	}
+	if s.Username != "" || s.Password != "" {
+		methods = append(methods, "username/password")
+	}
+	switch {
+	case len(methods) == 0:
+		return fmt.Errorf("an auth method is required: apikey (or apikey-file, apikey-command), bearer-token (or bearer-token-file), or username and password")
+	case len(methods) > 1:
+		return fmt.Errorf("auth methods are exclusive, got %s", strings.Join(methods, ", "))
+	case s.BearerToken != "" && s.BearerTokenFile != "":
+		return fmt.Errorf("bearer-token and bearer-token-file are exclusive, set only one")
+	case s.Username == "" && s.Password != "":
+		return fmt.Errorf("username is required with password")
+	case s.Username != "" && s.Password == "":
+		return fmt.Errorf("password is required with username")
+	}
+	return nil
+}
+
+// ValidateAPIKey rejects values that cannot be a DependencyTrack API key, e.g. a placeholder or a truncated key.
+func ValidateAPIKey(s string) error {
+	if !apiKeyRegexp.MatchString(s) {
+		return fmt.Errorf("apikey does not look like a DependencyTrack API key (at least 32 letters, digits, '_' or '-')")
+	}
+	return nil
+}
+
+// ValidateURL checks that url-api is the base URL of a DependencyTrack server.
+func ValidateURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid url-api: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url-api %q, expected http(s)://host[:port]", s)
+	}
+	if strings.HasSuffix(strings.TrimSuffix(u.Path, "/"), "/api") {
+		return fmt.Errorf("invalid url-api %q, expected the server URL without the /api path", s)
+	}
+	return nil
+}
+
+// Validate reports every problem of the project selection at once: a valid UUID, or a name and version.
+func (p Project) Validate() error {
+	if p.ProjectUUID != "" {
+		if _, err := uuid.Parse(p.ProjectUUID); err != nil {
+			return fmt.Errorf("invalid project-uuid %q: %w", p.ProjectUUID, err)
+		}
+		return nil
+	}
+	var errs []error
+	if p.ProjectName == "" {
+		errs = append(errs, fmt.Errorf("project-name is required without project-uuid"))
+	}
+	if p.ProjectVersion == "" {
+		errs = append(errs, fmt.Errorf("project-version is required without project-uuid"))
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateUpload reports every problem preventing an upload at once. Uploads name their project, the project UUID is
+// not used.
+func (c Config) ValidateUpload() error {
+	errs := []error{c.Server.Validate()}
+	if c.ProjectName == "" {
+		errs = append(errs, fmt.Errorf("project-name is required"))
+	}
+	if c.ProjectVersion == "" {
+		errs = append(errs, fmt.Errorf("project-version is required"))
+	}
+	if c.BOMFile == "" {
+		errs = append(errs, fmt.Errorf("bom-file is required"))
+	} else if info, err := os.Stat(c.BOMFile); err != nil {
+		errs = append(errs, fmt.Errorf("invalid bom-file: %w", err))
+	} else if info.IsDir() {
+		errs = append(errs, fmt.Errorf("invalid bom-file: %s is a directory", c.BOMFile))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testAPIKey = "odt_abcdefghijklmnopqrstuvwxyz0123456789"
+
+// checkErrors fails unless err contains each of want, or is nil when want is empty.
+func checkErrors(t *testing.T, err error, want []string) {
+	t.Helper()
+	if len(want) == 0 {
+		if err != nil {
+			t.Errorf("error = %v, want nil", err)
+		}
+		return
+	}
+	if err == nil {
+		t.Fatalf("error = nil, want %q", want)
+	}
+	for _, w := range want {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("error = %q, want it to contain %q", err, w)
+		}
+	}
+	if got := len(strings.Split(err.Error(), "\n")); got != len(want) {
+		t.Errorf("error = %q, want %d problems", err, len(want))
+	}
+}
+
+func TestHTTPSecretHeader(t *testing.T) {
+	h := HTTP{SecretHeaders: []string{"X-Tenant-Signature", " x-gateway-session "}}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "Authorization", want: true},
+		{name: "proxy-authorization", want: true},
+		{name: "Cookie", want: true},
+		{name: "X-Api-Key", want: true},
+		{name: "X-Auth-Token", want: true},
+		{name: "x-tenant-signature", want: true},
+		{name: "X-Gateway-Session", want: true},
+		{name: "X-Tenant", want: false},
+		{name: "X-Keyed-By", want: false},
+		{name: "Accept", want: false},
+	}
+	for _, tt := range tests {
+		if got := h.SecretHeader(tt.name); got != tt.want {
+			t.Errorf("SecretHeader(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestServerValidateAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  Server
+		wantErr []string
+	}{
+		{name: "apikey", server: Server{APIKey: testAPIKey}},
+		{name: "apikey file", server: Server{APIKeyFile: "/run/secrets/apikey"}},
+		{name: "apikey command", server: Server{APIKeyCommand: "pass show dt"}},
+		{name: "bearer token", server: Server{BearerToken: "eyJ"}},
+		{name: "bearer token file", server: Server{BearerTokenFile: "/run/secrets/token"}},
+		{name: "username and password", server: Server{Username: "ci", Password: "secret"}},
+		{name: "none", wantErr: []string{"an auth method is required"}},
+		{
+			name:    "apikey and bearer token",
+			server:  Server{APIKeyFile: "/run/secrets/apikey", BearerToken: "eyJ"},
+			wantErr: []string{"auth methods are exclusive, got apikey, bearer-token"},
+		},
+		{
+			name:    "every method",
+			server:  Server{APIKey: testAPIKey, BearerTokenFile: "/t", Password: "secret"},
+			wantErr: []string{"auth methods are exclusive, got apikey, bearer-token, username/password"},
+		},
+		{
+			name:    "bearer token twice",
+			server:  Server{BearerToken: "eyJ", BearerTokenFile: "/t"},
+			wantErr: []string{"bearer-token and bearer-token-file are exclusive"},
+		},
+		{name: "password alone", server: Server{Password: "secret"}, wantErr: []string{"username is required with password"}},
+		{name: "username alone", server: Server{Username: "ci"}, wantErr: []string{"password is required with username"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErrors(t, tt.server.ValidateAuth(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr []string
+	}{
+		{url: "https://dt.example.com"},
+		{url: "http://localhost:8081"},
+		{url: "https://example.com/dependencytrack/"},
+		{url: "dt.example.com", wantErr: []string{`invalid url-api "dt.example.com", expected http(s)://host[:port]`}},
+		{url: "ftp://dt.example.com", wantErr: []string{"expected http(s)://host[:port]"}},
+		{url: "https://", wantErr: []string{"expected http(s)://host[:port]"}},
+		{url: "https://dt.example.com/api", wantErr: []string{"without the /api path"}},
+		{url: "https://dt.example.com/api/", wantErr: []string{"without the /api path"}},
+		{url: "https://dt.example.com:port", wantErr: []string{"invalid url-api: "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			checkErrors(t, ValidateURL(tt.url), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{key: testAPIKey},
+		{key: "abcdefghijklmnopqrstuvwxyz012345"},
+		{key: "odt_abc-DEF_0123456789abcdefghijkl"},
+		{key: "abcdefghijklmnopqrstuvwxyz01234", wantErr: true},
+		{key: "<API_KEY>", wantErr: true},
+		{key: testAPIKey + " ", wantErr: true},
+		{key: "odt_abcdefghijklmnopqrstuvwxyz0123456789\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if err := ValidateAPIKey(tt.key); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAPIKey() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServerValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  Server
+		wantErr []string
+	}{
+		{name: "valid", server: Server{URLAPI: "https://dt.example.com", APIKey: testAPIKey}},
+		{name: "valid username", server: Server{URLAPI: "https://dt.example.com", Username: "ci", Password: "short"}},
+		{
+			name:    "invalid apikey",
+			server:  Server{URLAPI: "https://dt.example.com", APIKey: "changeme"},
+			wantErr: []string{"apikey does not look like a DependencyTrack API key"},
+		},
+		{
+			name:   "every problem at once",
+			server: Server{TLS: TLS{ClientCert: "/missing/cert.pem", MinVersion: "1.1"}, HTTP: HTTP{Timeout: -1}},
+			wantErr: []string{
+				"url-api is required",
+				"invalid client-cert: ",
+				"client-cert and client-key must be set together",
+				`invalid tls-min-version "1.1"`,
+				"invalid timeout",
+				"an auth method is required",
+			},
+		},
+		{
+			name:    "invalid url and exclusive auth",
+			server:  Server{URLAPI: "dt.example.com/api", APIKey: "changeme", BearerToken: "eyJ"},
+			wantErr: []string{"invalid url-api", "auth methods are exclusive"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErrors(t, tt.server.Validate(), tt.wantErr)
+		})
+	}
+}
+
+func TestConfigValidateUpload(t *testing.T) {
+	dir := t.TempDir()
+	bom := filepath.Join(dir, "sbom.json")
+	if err := os.WriteFile(bom, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	server := Server{URLAPI: "https://dt.example.com", APIKey: testAPIKey}
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr []string
+	}{
+		{
+			name:   "valid",
+			config: Config{Server: server, Project: Project{ProjectName: "web", ProjectVersion: "main"}, Upload: Upload{BOMFile: bom}},
+		},
+		{
+			name:    "project uuid is not enough",
+			config:  Config{Server: server, Project: Project{ProjectUUID: "5e0f5fd4-7ba0-4ac5-8fb8-a8e1ac7a6f7e"}, Upload: Upload{BOMFile: bom}},
+			wantErr: []string{"project-name is required", "project-version is required"},
+		},
+		{
+			name:    "missing bom file",
+			config:  Config{Server: server, Project: Project{ProjectName: "web", ProjectVersion: "main"}, Upload: Upload{BOMFile: filepath.Join(dir, "missing.json")}},
+			wantErr: []string{"invalid bom-file: "},
+		},
+		{
+			name:    "bom file is a directory",
+			config:  Config{Server: server, Project: Project{ProjectName: "web", ProjectVersion: "main"}, Upload: Upload{BOMFile: dir}},
+			wantErr: []string{"is a directory"},
+		},
+		{
+			name:   "server and upload problems together",
+			config: Config{Server: Server{APIKey: testAPIKey}},
+			wantErr: []string{
+				"url-api is required",
+				"project-name is required",
+				"project-version is required",
+				"bom-file is required",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErrors(t, tt.config.ValidateUpload(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const templateHeader = `# trivy-plugin-dependencytrack config file.
+#
+# Every key is commented out with its default value, uncomment and edit the ones to set.
+# Flags and %s_* environment variables take precedence over this file.
+# Check it with: trivy dependencytrack config validate
+`
+
+const templateFooter = `
+# Profiles override the keys above for one DependencyTrack instance, selected with --profile, the
+# %s_PROFILE environment variable or the profile key above.
+# profiles:
+#   staging:
+#     url-api: https://dependencytrack.staging.example.com
+#     apikey: <API_KEY>
+#   production:
+#     url-api: https://dependencytrack.example.com
+#     apikey: <API_KEY>
+#     auto-create: false
+
+# Auto-triage rules, applied by "triage apply-rules" and after uploads before the rules of triage-rules-file.
+# triage-rules:
+#   - name: test-dependencies
+#     match:
+#       scopes: [optional, excluded]
+#     analysis:
+#       state: NOT_AFFECTED
+#       justification: CODE_NOT_REACHABLE
+#       suppress: true
+`
+
+// WriteTemplate writes a commented config file listing the keys with their description, environment variable and
+// default value.
+func WriteTemplate(w io.Writer, envPrefix string, keys []Key) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, templateHeader, envPrefix)
+	for _, k := range keys {
+		if k.Type == TypeMap || k.Type == TypeList {
+			continue
+		}
+		b.WriteString("\n")
+		for _, line := range strings.Split(k.Description, "\n") {
+			fmt.Fprintf(&b, "# %s\n", line)
+		}
+		fmt.Fprintf(&b, "# Env: %s\n", EnvName(envPrefix, k.Name))
+		fmt.Fprintf(&b, "# %s: %s\n", k.Name, templateValue(k))
+	}
+	fmt.Fprintf(&b, templateFooter, envPrefix)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func templateValue(k Key) string {
+	switch k.Type {
+	case "bool", "int", "float64":
+		return k.Default
+	case "stringSlice", "stringArray":
+		return "[]"
+	}
+	if k.Default == "" {
+		return `""`
+	}
+	return strconv.Quote(k.Default)
+}
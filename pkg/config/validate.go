@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Problem is an invalid setting, found in a config file or in the environment.
+type Problem struct {
+	// Source is the config file, with the profile if any, or "env".
+	Source  string
+	Key     string
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Source, p.Key, p.Message)
+}
+
+// Validation checks config values against a registry of keys.
+type Validation struct {
+	Registry Registry
+	// Validators check the value of a key, as a string, once its type is checked.
+	Validators map[string]func(value string) error
+	// Structured validate the decoded value of the map and list keys.
+	Structured map[string]func(value any) error
+	// ExtraEnv lists the environment variables of the prefix that are not config keys, e.g. the config file one.
+	ExtraEnv []string
+}
+
+// File validates the decoded config file at path, profiles included: unknown keys, wrong types and invalid values.
+func (v Validation) File(path string, values map[string]any) []Problem {
+	problems := v.values(path, values, true)
+	profiles, ok := values["profiles"].(map[string]any)
+	if !ok {
+		return problems
+	}
+	for _, name := range slices.Sorted(maps.Keys(profiles)) {
+		source := fmt.Sprintf("%s (profile %s)", path, name)
+		profile, ok := profiles[name].(map[string]any)
+		if !ok {
+			if profiles[name] != nil {
+				problems = append(problems, Problem{Source: source, Key: "profiles." + name, Message: "expected a map of config keys"})
+			}
+			continue
+		}
+		problems = append(problems, v.values(source, profile, false)...)
+	}
+	return problems
+}
+
+func (v Validation) values(source string, values map[string]any, top bool) []Problem {
+	var problems []Problem
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		key, ok := v.Registry[name]
+		if !ok {
+			problems = append(problems, Problem{Source: source, Key: name, Message: v.unknown(name)})
+			continue
+		}
+		if !top && (name == "profile" || name == "profiles") {
+			problems = append(problems, Problem{Source: source, Key: name, Message: "cannot be set within a profile"})
+			continue
+		}
+		if name == "profiles" {
+			if _, ok := values[name].(map[string]any); !ok && values[name] != nil {
+				problems = append(problems, Problem{Source: source, Key: name, Message: "expected a map of profile names to config keys"})
+			}
+			continue
+		}
+		if err := v.check(key, values[name]); err != nil {
+			problems = append(problems, Problem{Source: source, Key: name, Message: err.Error()})
+		}
+	}
+	return problems
+}
+
+// Env validates the environment variables starting with the prefix: unknown variables, wrong types and invalid
+// values.
+func (v Validation) Env(prefix string, environ []string) []Problem {
+	byEnv := map[string]Key{}
+	for _, k := range v.Registry {
+		byEnv[EnvName(prefix, k.Name)] = k
+	}
+
+	var problems []Problem
+	slices.Sort(environ)
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, prefix+"_") || slices.Contains(v.ExtraEnv, name) {
+			continue
+		}
+		key, ok := byEnv[name]
+		if !ok {
+			guess := strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(name, prefix+"_"), "_", "-"))
+			message := "unknown environment variable"
+			if s := v.Registry.Suggest(guess); s != "" {
+				message += fmt.Sprintf(", did you mean %s?", EnvName(prefix, s))
+			}
+			problems = append(problems, Problem{Source: "env", Key: name, Message: message})
+			continue
+		}
+		if key.Type == TypeMap || key.Type == TypeList {
+			continue
+		}
+		if err := v.check(key, value); err != nil {
+			problems = append(problems, Problem{Source: "env", Key: name, Message: err.Error()})
+		}
+	}
+	return problems
+}
+
+func (v Validation) unknown(name string) string {
+	if s := v.Registry.Suggest(name); s != "" {
+		return fmt.Sprintf("unknown key, did you mean %s?", s)
+	}
+	return "unknown key"
+}
+
+func (v Validation) check(key Key, value any) error {
+	if value == nil {
+		return nil
+	}
+	if key.Type == TypeMap || key.Type == TypeList {
+		if validate, ok := v.Structured[key.Name]; ok {
+			return validate(value)
+		}
+		return nil
+	}
+	s, err := scalar(key.Type, value)
+	if err != nil {
+		return err
+	}
+	if validate, ok := v.Validators[key.Name]; ok && s != "" {
+		return validate(s)
+	}
+	return nil
+}
+
+// scalar checks the type of a value and returns it as a string. Slices are returned empty.
+func scalar(typ string, value any) (string, error) {
+	switch typ {
+	case "stringSlice", "stringArray":
+		switch value := value.(type) {
+		case string:
+			return "", nil
+		case []any:
+			for _, item := range value {
+				if _, err := scalar("string", item); err != nil {
+					return "", fmt.Errorf("expected a list of strings")
+				}
+			}
+			return "", nil
+		}
+		return "", fmt.Errorf("expected a list of strings, got %s", describe(value))
+	}
+
+	var s string
+	switch value := value.(type) {
+	case string:
+		s = value
+	case bool, int, int64, uint64, float64:
+		s = fmt.Sprint(value)
+	default:
+		return "", fmt.Errorf("expected a %s, got %s", describeType(typ), describe(value))
+	}
+
+	switch typ {
+	case "bool":
+		if _, err := strconv.ParseBool(s); err != nil {
+			return "", fmt.Errorf("expected a boolean (true or false), got %q", s)
+		}
+	case "int":
+		if _, err := strconv.Atoi(s); err != nil {
+			return "", fmt.Errorf("expected an integer, got %q", s)
+		}
+	case "duration":
+		if _, err := time.ParseDuration(s); err != nil {
+			return "", fmt.Errorf("expected a duration such as 90s or 5m, got %q", s)
+		}
+	}
+	return s, nil
+}
+
+func describeType(typ string) string {
+	switch typ {
+	case "bool":
+		return "boolean"
+	case "int":
+		return "integer"
+	}
+	return typ
+}
+
+func describe(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "a map"
+	case []any:
+		return "a list"
+	}
+	return fmt.Sprintf("%v", value)
+}
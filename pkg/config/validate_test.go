@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testValidation() Validation {
+	registry := Registry{}
+	for _, k := range []Key{
+		{Name: "url-api", Type: "string", Flag: "url-api"},
+		{Name: "timeout", Type: "duration", Flag: "timeout"},
+		{Name: "auto-create", Type: "bool", Flag: "auto-create"},
+		{Name: "retries", Type: "int", Flag: "retries"},
+		{Name: "tags", Type: "stringSlice", Flag: "tags"},
+		{Name: "log-level", Type: "string", Flag: "log-level"},
+		{Name: "profile", Type: "string", Flag: "profile"},
+		{Name: "profiles", Type: TypeMap},
+		{Name: "triage-rules", Type: TypeList},
+	} {
+		registry[k.Name] = k
+	}
+	return Validation{
+		Registry: registry,
+		Validators: map[string]func(string) error{
+			"log-level": func(s string) error {
+				if !slices.Contains([]string{"debug", "info", "warn", "error"}, strings.ToLower(s)) {
+					return fmt.Errorf("invalid value %q, expected one of [debug info warn error]", s)
+				}
+				return nil
+			},
+		},
+		Structured: map[string]func(any) error{
+			"triage-rules": func(v any) error {
+				if _, ok := v.([]any); !ok {
+					return fmt.Errorf("expected a list of rules")
+				}
+				return nil
+			},
+		},
+		ExtraEnv: []string{"TRIVY_PLUGIN_DEPENDENCYTRACK_CONFIG"},
+	}
+}
+
+func problemStrings(problems []Problem) []string {
+	var res []string
+	for _, p := range problems {
+		res = append(res, p.String())
+	}
+	return res
+}
+
+func TestValidationFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]any
+		want   []string
+	}{
+		{
+			name: "valid",
+			values: map[string]any{
+				"url-api": "https://dt.example.com", "timeout": "90s", "auto-create": true, "retries": 3,
+				"tags": []any{"a", "b"}, "log-level": "INFO", "triage-rules": []any{map[string]any{"name": "x"}},
+				"profiles": map[string]any{"staging": map[string]any{"url-api": "https://staging.example.com"}, "empty": nil},
+			},
+		},
+		{
+			name:   "unknown keys",
+			values: map[string]any{"url_api": "x", "timeuot": "1m", "colour": "red"},
+			want: []string{
+				"c.yaml: colour: unknown key",
+				"c.yaml: timeuot: unknown key, did you mean timeout?",
+				"c.yaml: url_api: unknown key, did you mean url-api?",
+			},
+		},
+		{
+			name: "wrong types",
+			values: map[string]any{
+				"timeout": "5 minutes", "auto-create": "yes", "retries": 1.5, "tags": map[string]any{"a": 1},
+				"url-api": []any{"x"}, "triage-rules": "none", "profiles": "staging",
+			},
+			want: []string{
+				`c.yaml: auto-create: expected a boolean (true or false), got "yes"`,
+				`c.yaml: profiles: expected a map of profile names to config keys`,
+				`c.yaml: retries: expected an integer, got "1.5"`,
+				"c.yaml: tags: expected a list of strings, got a map",
+				`c.yaml: timeout: expected a duration such as 90s or 5m, got "5 minutes"`,
+				"c.yaml: triage-rules: expected a list of rules",
+				"c.yaml: url-api: expected a string, got a list",
+			},
+		},
+		{
+			name:   "invalid enum value",
+			values: map[string]any{"log-level": "verbose"},
+			want:   []string{`c.yaml: log-level: invalid value "verbose", expected one of [debug info warn error]`},
+		},
+		{
+			name: "profiles",
+			values: map[string]any{
+				"profiles": map[string]any{
+					"staging": map[string]any{"timeout": "soon", "profile": "prod", "urlapi": "x"},
+					"broken":  "prod",
+				},
+			},
+			want: []string{
+				"c.yaml (profile broken): profiles.broken: expected a map of config keys",
+				"c.yaml (profile staging): profile: cannot be set within a profile",
+				`c.yaml (profile staging): timeout: expected a duration such as 90s or 5m, got "soon"`,
+				"c.yaml (profile staging): urlapi: unknown key, did you mean url-api?",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := problemStrings(testValidation().File("c.yaml", tt.values))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("File() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestValidationEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		environ []string
+		want    []string
+	}{
+		{
+			name: "valid",
+			environ: []string{
+				"TRIVY_PLUGIN_DEPENDENCYTRACK_URL_API=https://dt.example.com",
+				"TRIVY_PLUGIN_DEPENDENCYTRACK_TIMEOUT=2m",
+				"TRIVY_PLUGIN_DEPENDENCYTRACK_TAGS=a,b",
+				"TRIVY_PLUGIN_DEPENDENCYTRACK_CONFIG=./c.yaml",
+				"TRIVY_PLUGIN_DEPENDENCYTRACK_TRIAGE_RULES=ignored",
+				"HOME=/root",
+				"TRIVY_PLUGIN_OTHER=1",
+			},
+		},
+		{
+			name: "unknown variables",
+			environ: []string{
+				"TRIVY_PLUGIN_DEPENDENCYTRACK_URLAPI=x",
+				"TRIVY_PLUGIN_DEPENDENCYTRACK_COLOUR=red",
+			},
+			want: []string{
+				"env: TRIVY_PLUGIN_DEPENDENCYTRACK_COLOUR: unknown environment variable",
+				"env: TRIVY_PLUGIN_DEPENDENCYTRACK_URLAPI: unknown environment variable, did you mean TRIVY_PLUGIN_DEPENDENCYTRACK_URL_API?",
+			},
+		},
+		{
+			name: "invalid values",
+			environ: []string{
+				"TRIVY_PLUGIN_DEPENDENCYTRACK_TIMEOUT=forever",
+				"TRIVY_PLUGIN_DEPENDENCYTRACK_AUTO_CREATE=maybe",
+				"TRIVY_PLUGIN_DEPENDENCYTRACK_LOG_LEVEL=trace",
+				"TRIVY_PLUGIN_DEPENDENCYTRACK_RETRIES=",
+			},
+			want: []string{
+				`env: TRIVY_PLUGIN_DEPENDENCYTRACK_AUTO_CREATE: expected a boolean (true or false), got "maybe"`,
+				`env: TRIVY_PLUGIN_DEPENDENCYTRACK_LOG_LEVEL: invalid value "trace", expected one of [debug info warn error]`,
+				`env: TRIVY_PLUGIN_DEPENDENCYTRACK_RETRIES: expected an integer, got ""`,
+				`env: TRIVY_PLUGIN_DEPENDENCYTRACK_TIMEOUT: expected a duration such as 90s or 5m, got "forever"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := problemStrings(testValidation().Env("TRIVY_PLUGIN_DEPENDENCYTRACK", tt.environ))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Env() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
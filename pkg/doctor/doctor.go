@@ -0,0 +1,198 @@
+// Package doctor checks that DependencyTrack can be reached and used, from the name resolution of its host to the
+// permissions of the credentials.
+package doctor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+// Status of a check.
+type Status string
+
+const (
+	Pass Status = "PASS"
+	Warn Status = "WARN"
+	Fail Status = "FAIL"
+	Skip Status = "SKIP"
+)
+
+// Result is the outcome of one check.
+type Result struct {
+	Check  string
+	Status Status
+	Detail string
+}
+
+// Failed reports whether any check failed.
+func Failed(results []Result) bool {
+	return slices.ContainsFunc(results, func(r Result) bool { return r.Status == Fail })
+}
+
+// Write renders the results as an aligned text table.
+func Write(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tDETAIL")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Check, r.Status, r.Detail)
+	}
+	return tw.Flush()
+}
+
+// DNS resolves host, an IP address passing as is.
+func DNS(ctx context.Context, host string) Result {
+	if net.ParseIP(host) != nil {
+		return Result{Check: "dns", Status: Pass, Detail: host + " is an IP address"}
+	}
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return Result{Check: "dns", Status: Fail, Detail: err.Error()}
+	}
+	return Result{Check: "dns", Status: Pass, Detail: fmt.Sprintf("%s resolves to %s", host, strings.Join(addrs, ", "))}
+}
+
+// TCP opens a connection to address, as host:port.
+func TCP(ctx context.Context, address string, timeout time.Duration) Result {
+	start := time.Now()
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return Result{Check: "tcp", Status: Fail, Detail: err.Error()}
+	}
+	defer conn.Close()
+	return Result{Check: "tcp", Status: Pass, Detail: fmt.Sprintf("connected to %s in %s", address, time.Since(start).Round(time.Millisecond))}
+}
+
+// certificateExpiryWarning is how long before its expiry a server certificate is reported.
+const certificateExpiryWarning = 14 * 24 * time.Hour
+
+// TLS performs a handshake with address, as host:port, verifying the certificate for serverName unless the TLS
+// configuration skips it.
+func TLS(ctx context.Context, address string, serverName string, config *tls.Config, timeout time.Duration) Result {
+	config = config.Clone()
+	config.ServerName = serverName
+	dialer := tls.Dialer{NetDialer: &net.Dialer{Timeout: timeout}, Config: config}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		detail := err.Error()
+		var unknownAuthority x509.UnknownAuthorityError
+		if errors.As(err, &unknownAuthority) {
+			detail += ", set ca-cert to the CA of the server"
+		}
+		return Result{Check: "tls", Status: Fail, Detail: detail}
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	leaf := state.PeerCertificates[0]
+	detail := fmt.Sprintf("%s, certificate %s issued by %s, expires %s", tls.VersionName(state.Version),
+		leaf.Subject.CommonName, leaf.Issuer.CommonName, leaf.NotAfter.Format(time.DateOnly))
+	switch {
+	case config.InsecureSkipVerify:
+		return Result{Check: "tls", Status: Warn, Detail: detail + ", NOT verified (insecure-skip-verify)"}
+	case time.Until(leaf.NotAfter) < certificateExpiryWarning:
+		return Result{Check: "tls", Status: Warn, Detail: detail + ", expires soon"}
+	}
+	return Result{Check: "tls", Status: Pass, Detail: detail}
+}
+
+// Version calls the version endpoint, which needs no authentication.
+func Version(ctx context.Context, client *dtrack.Client) Result {
+	about, err := client.About.Get(ctx)
+	if err != nil {
+		return Result{Check: "version", Status: Fail, Detail: err.Error()}
+	}
+	return Result{Check: "version", Status: Pass, Detail: fmt.Sprintf("%s %s, %s %s", about.Application, about.Version,
+		about.Framework.Name, about.Framework.Version)}
+}
+
+// TeamSelf returns the team of the API key sent by httpClient, from an endpoint the DependencyTrack client does not
+// cover.
+func TeamSelf(ctx context.Context, httpClient *http.Client, baseURL string) (dtrack.Team, error) {
+	endpoint, err := url.JoinPath(baseURL, "api/v1/team/self")
+	if err != nil {
+		return dtrack.Team{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return dtrack.Team{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return dtrack.Team{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		return dtrack.Team{}, fmt.Errorf("the API key is invalid or expired (status: %d)", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return dtrack.Team{}, fmt.Errorf("unexpected answer of %s (status: %d): %s", endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var team dtrack.Team
+	err = json.NewDecoder(resp.Body).Decode(&team)
+	if err != nil {
+		return dtrack.Team{}, fmt.Errorf("failed to decode team: %w", err)
+	}
+	return team, nil
+}
+
+// Requirement is a permission used by the upload commands.
+type Requirement struct {
+	Permission string
+	Reason     string
+	// Required makes a missing permission fail the check, otherwise it is a warning.
+	Required bool
+}
+
+// UploadRequirements lists the permissions used by the upload commands, project creation being required with
+// auto-create only, and reading the findings when a gate or a summary is configured.
+func UploadRequirements(autoCreate bool, readsFindings bool) []Requirement {
+	return []Requirement{
+		{Permission: "BOM_UPLOAD", Reason: "upload sboms", Required: true},
+		{Permission: "PROJECT_CREATION_UPLOAD", Reason: "create missing projects with auto-create", Required: autoCreate},
+		{Permission: "VIEW_VULNERABILITY", Reason: "read findings for gates, summaries and VEX", Required: readsFindings},
+	}
+}
+
+// Permissions checks each requirement against the granted permissions.
+func Permissions(granted []dtrack.Permission, requirements []Requirement) []Result {
+	var results []Result
+	for _, req := range requirements {
+		r := Result{Check: "permission " + req.Permission, Status: Pass, Detail: "granted, to " + req.Reason}
+		if !slices.ContainsFunc(granted, func(p dtrack.Permission) bool { return p.Name == req.Permission }) {
+			r.Status = Warn
+			if req.Required {
+				r.Status = Fail
+			}
+			r.Detail = "missing, needed to " + req.Reason
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// PermissionNames lists the names of the permissions, sorted.
+func PermissionNames(permissions []dtrack.Permission) []string {
+	names := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		names = append(names, p.Name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,46 @@
+package doctor
+
+import (
+	"testing"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+func TestPermissionsUploadRequirements(t *testing.T) {
+	granted := []dtrack.Permission{{Name: "BOM_UPLOAD"}}
+
+	tests := []struct {
+		name          string
+		autoCreate    bool
+		readsFindings bool
+		want          map[string]Status
+	}{
+		{
+			name: "plain upload",
+			want: map[string]Status{"BOM_UPLOAD": Pass, "PROJECT_CREATION_UPLOAD": Warn, "VIEW_VULNERABILITY": Warn},
+		},
+		{
+			name:       "auto-create",
+			autoCreate: true,
+			want:       map[string]Status{"BOM_UPLOAD": Pass, "PROJECT_CREATION_UPLOAD": Fail, "VIEW_VULNERABILITY": Warn},
+		},
+		{
+			name:          "gate or summary",
+			readsFindings: true,
+			want:          map[string]Status{"BOM_UPLOAD": Pass, "PROJECT_CREATION_UPLOAD": Warn, "VIEW_VULNERABILITY": Fail},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Permissions(granted, UploadRequirements(tt.autoCreate, tt.readsFindings))
+			if len(results) != len(tt.want) {
+				t.Fatalf("Permissions() = %+v, want %d results", results, len(tt.want))
+			}
+			for _, r := range results {
+				if want := tt.want[r.Check[len("permission "):]]; r.Status != want {
+					t.Errorf("%s = %s, want %s", r.Check, r.Status, want)
+				}
+			}
+		})
+	}
+}
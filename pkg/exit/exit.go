@@ -0,0 +1,118 @@
+// Package exit classifies the errors of the commands, each kind exiting with its own code so that CI can tell a
+// misconfiguration from an outage or a failed gate.
+package exit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+// Kind of error, with its exit code.
+type Kind int
+
+// Exit codes, documented in the help of the root command and the README.
+const (
+	// KindOther is an unexpected error.
+	KindOther Kind = 1
+	// KindConfig is an invalid flag, environment variable, config file or setting.
+	KindConfig Kind = 2
+	// KindAuth is a DependencyTrack rejection of the credentials or a missing permission.
+	KindAuth Kind = 3
+	// KindNetwork is a DependencyTrack server that cannot be reached: DNS, connection, TLS or request timeout.
+	KindNetwork Kind = 4
+	// KindServer is a DependencyTrack rejection or failure of a request.
+	KindServer Kind = 5
+	// KindTimeout is an upload, analysis or VEX that DependencyTrack did not process in time.
+	KindTimeout Kind = 6
+	// KindGate is a vulnerability gate that failed.
+	KindGate Kind = 7
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindConfig:
+		return "config"
+	case KindAuth:
+		return "auth"
+	case KindNetwork:
+		return "network"
+	case KindServer:
+		return "server"
+	case KindTimeout:
+		return "processing-timeout"
+	case KindGate:
+		return "gate"
+	}
+	return "other"
+}
+
+// Error is an error of a known kind.
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func wrap(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// Config marks err as a configuration error. A nil err stays nil, as for the other constructors.
+func Config(err error) error { return wrap(KindConfig, err) }
+
+// Auth marks err as an authentication or authorization error.
+func Auth(err error) error { return wrap(KindAuth, err) }
+
+// Network marks err as a network error.
+func Network(err error) error { return wrap(KindNetwork, err) }
+
+// Server marks err as a server rejection or failure.
+func Server(err error) error { return wrap(KindServer, err) }
+
+// Timeout marks err as a processing timeout.
+func Timeout(err error) error { return wrap(KindTimeout, err) }
+
+// Gate marks err as a failed vulnerability gate.
+func Gate(err error) error { return wrap(KindGate, err) }
+
+// KindOf returns the kind of err: the marked one, else the one of a DependencyTrack answer or of a network error.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	var apiErr *dtrack.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden {
+			return KindAuth
+		}
+		return KindServer
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return KindNetwork
+	}
+	return KindOther
+}
+
+// Code returns the exit code of err, 0 when nil.
+func Code(err error) int {
+	if err == nil {
+		return 0
+	}
+	return int(KindOf(err))
+}
@@ -0,0 +1,77 @@
+package exit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"testing"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+func TestCode(t *testing.T) {
+	dnsErr := &net.DNSError{Err: "no such host", Name: "dt.example.com", IsNotFound: true}
+
+	tests := []struct {
+		name string
+		err  error
+		want Kind
+	}{
+		{name: "401", err: &dtrack.APIError{StatusCode: 401}, want: KindAuth},
+		{name: "403", err: &dtrack.APIError{StatusCode: 403}, want: KindAuth},
+		{name: "404", err: &dtrack.APIError{StatusCode: 404}, want: KindServer},
+		{name: "409", err: &dtrack.APIError{StatusCode: 409}, want: KindServer},
+		{name: "500", err: &dtrack.APIError{StatusCode: 500}, want: KindServer},
+		{name: "wrapped api error", err: fmt.Errorf("failed to upload bom: %w", &dtrack.APIError{StatusCode: 403}), want: KindAuth},
+		{name: "dns error", err: dnsErr, want: KindNetwork},
+		{name: "url error", err: &url.Error{Op: "Get", URL: "https://dt.example.com", Err: dnsErr}, want: KindNetwork},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", errors.New("connection refused"))}, want: KindNetwork},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, want: KindNetwork},
+		{name: "wrapped deadline exceeded", err: fmt.Errorf("failed to get version: %w", context.DeadlineExceeded), want: KindNetwork},
+		{name: "config", err: Config(errors.New("url-api is required")), want: KindConfig},
+		{name: "timeout", err: Timeout(errors.New("analysis not completed")), want: KindTimeout},
+		{name: "gate", err: Gate(errors.New("2 critical vulnerabilities")), want: KindGate},
+		{name: "wrapped kind", err: fmt.Errorf("upload: %w", Config(errors.New("bom-file is required"))), want: KindConfig},
+		{name: "kind wrapped twice", err: fmt.Errorf("a: %w", fmt.Errorf("b: %w", Gate(errors.New("failed")))), want: KindGate},
+		{name: "marked kind wins over the api error", err: Timeout(&dtrack.APIError{StatusCode: 500}), want: KindTimeout},
+		{name: "joined errors", err: errors.Join(errors.New("first"), Config(errors.New("second"))), want: KindConfig},
+		{name: "unexpected", err: errors.New("boom"), want: KindOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KindOf(tt.err); got != tt.want {
+				t.Errorf("KindOf() = %s, want %s", got, tt.want)
+			}
+			if got := Code(tt.err); got != int(tt.want) {
+				t.Errorf("Code() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeNil(t *testing.T) {
+	if got := Code(nil); got != 0 {
+		t.Errorf("Code(nil) = %d, want 0", got)
+	}
+	for _, mark := range []func(error) error{Config, Auth, Network, Server, Timeout, Gate} {
+		if err := mark(nil); err != nil {
+			t.Errorf("marking nil = %v, want nil", err)
+		}
+	}
+}
+
+func TestErrorUnwrap(t *testing.T) {
+	cause := &dtrack.APIError{StatusCode: 500}
+	err := Server(fmt.Errorf("failed to upload bom: %w", cause))
+
+	var apiErr *dtrack.APIError
+	if !errors.As(err, &apiErr) || apiErr != cause {
+		t.Errorf("errors.As() did not find the cause of %v", err)
+	}
+	if err.Error() != "failed to upload bom: "+cause.Error() {
+		t.Errorf("Error() = %q, want the message of the wrapped error", err.Error())
+	}
+}
@@ -0,0 +1,105 @@
+// Package findings fetches DependencyTrack findings and policy violations and compares them between project versions.
+package findings
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	dtrack "github.com/DependencyTrack/client-go"
+	"github.com/google/uuid"
+)
+
+// Diff holds the findings of a project version split against a baseline version.
+type Diff struct {
+	Introduced []dtrack.Finding
+	Fixed      []dtrack.Finding
+	Unchanged  []dtrack.Finding
+}
+
+// ViolationDiff holds the policy violations of a project version split against a baseline version.
+type ViolationDiff struct {
+	Introduced []dtrack.PolicyViolation
+	Fixed      []dtrack.PolicyViolation
+	Unchanged  []dtrack.PolicyViolation
+}
+
+// Fetch returns every unsuppressed finding of a project.
+func Fetch(ctx context.Context, client *dtrack.Client, projectUUID uuid.UUID) ([]dtrack.Finding, error) {
+	return dtrack.FetchAll(func(po dtrack.PageOptions) (dtrack.Page[dtrack.Finding], error) {
+		return client.Finding.GetAll(ctx, projectUUID, false, po)
+	})
+}
+
+// FetchViolations returns every unsuppressed policy violation of a project.
+func FetchViolations(ctx context.Context, client *dtrack.Client, projectUUID uuid.UUID) ([]dtrack.PolicyViolation, error) {
+	return dtrack.FetchAll(func(po dtrack.PageOptions) (dtrack.Page[dtrack.PolicyViolation], error) {
+		return client.PolicyViolation.GetAllForProject(ctx, projectUUID, false, po)
+	})
+}
+
+// Key identifies a finding across project versions. The component version is left out on purpose so that a
+// vulnerability still present after a component upgrade is reported as unchanged.
+func Key(f dtrack.Finding) string {
+	return strings.Join([]string{f.Vulnerability.Source, f.Vulnerability.VulnID, f.Component.Group, f.Component.Name}, "|")
+}
+
+// ViolationKey identifies a policy violation across project versions.
+func ViolationKey(v dtrack.PolicyViolation) string {
+	condition := ""
+	if v.PolicyCondition != nil {
+		condition = v.PolicyCondition.UUID.String()
+	}
+	return strings.Join([]string{v.Type, condition, v.Component.Group, v.Component.Name}, "|")
+}
+
+// Compare splits the current findings into introduced and unchanged ones, and reports the baseline findings that
+// disappeared as fixed.
+func Compare(current []dtrack.Finding, baseline []dtrack.Finding) Diff {
+	var d Diff
+	d.Introduced, d.Unchanged, d.Fixed = split(current, baseline, Key)
+	SortBySeverity(d.Introduced)
+	SortBySeverity(d.Fixed)
+	SortBySeverity(d.Unchanged)
+	return d
+}
+
+// CompareViolations is the policy violation counterpart of Compare.
+func CompareViolations(current []dtrack.PolicyViolation, baseline []dtrack.PolicyViolation) ViolationDiff {
+	var d ViolationDiff
+	d.Introduced, d.Unchanged, d.Fixed = split(current, baseline, ViolationKey)
+	return d
+}
+
+// SortBySeverity orders findings from the most to the least severe, then by vulnerability ID.
+func SortBySeverity(f []dtrack.Finding) {
+	sort.SliceStable(f, func(i, j int) bool {
+		if f[i].Vulnerability.SeverityRank != f[j].Vulnerability.SeverityRank {
+			return f[i].Vulnerability.SeverityRank < f[j].Vulnerability.SeverityRank
+		}
+		return f[i].Vulnerability.VulnID < f[j].Vulnerability.VulnID
+	})
+}
+
+func split[T any](current []T, baseline []T, key func(T) string) (introduced []T, unchanged []T, fixed []T) {
+	baselineKeys := make(map[string]bool, len(baseline))
+	for _, b := range baseline {
+		baselineKeys[key(b)] = true
+	}
+	currentKeys := make(map[string]bool, len(current))
+	for _, c := range current {
+		k := key(c)
+		currentKeys[k] = true
+		if baselineKeys[k] {
+			unchanged = append(unchanged, c)
+		} else {
+			introduced = append(introduced, c)
+		}
+	}
+	for _, b := range baseline {
+		if !currentKeys[key(b)] {
+			fixed = append(fixed, b)
+		}
+	}
+	return introduced, unchanged, fixed
+}
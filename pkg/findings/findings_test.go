@@ -0,0 +1,205 @@
+package findings
+
+import (
+	"slices"
+	"testing"
+
+	dtrack "github.com/DependencyTrack/client-go"
+	"github.com/google/uuid"
+)
+
+func finding(vulnID string, component string, version string, rank int) dtrack.Finding {
+	return dtrack.Finding{
+		Component:     dtrack.FindingComponent{Name: component, Version: version},
+		Vulnerability: dtrack.FindingVulnerability{Source: "NVD", VulnID: vulnID, SeverityRank: rank},
+	}
+}
+
+func vulnIDs(findings []dtrack.Finding) []string {
+	ids := []string{}
+	for _, f := range findings {
+		ids = append(ids, f.Vulnerability.VulnID)
+	}
+	return ids
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name           string
+		current        []dtrack.Finding
+		baseline       []dtrack.Finding
+		wantIntroduced []string
+		wantFixed      []string
+		wantUnchanged  []string
+	}{
+		{
+			name: "split against the baseline",
+			current: []dtrack.Finding{
+				finding("CVE-1", "openssl", "3.0.1", 1),
+				finding("CVE-2", "zlib", "1.2", 2),
+			},
+			baseline: []dtrack.Finding{
+				finding("CVE-1", "openssl", "3.0.1", 1),
+				finding("CVE-3", "curl", "8.0", 0),
+			},
+			wantIntroduced: []string{"CVE-2"},
+			wantFixed:      []string{"CVE-3"},
+			wantUnchanged:  []string{"CVE-1"},
+		},
+		{
+			name:           "missing baseline reports every finding as introduced",
+			current:        []dtrack.Finding{finding("CVE-1", "openssl", "3.0.1", 1), finding("CVE-2", "zlib", "1.2", 2)},
+			wantIntroduced: []string{"CVE-1", "CVE-2"},
+			wantFixed:      []string{},
+			wantUnchanged:  []string{},
+		},
+		{
+			name:           "component upgrade keeping the vulnerability is unchanged",
+			current:        []dtrack.Finding{finding("CVE-1", "openssl", "3.0.2", 1)},
+			baseline:       []dtrack.Finding{finding("CVE-1", "openssl", "3.0.1", 1)},
+			wantIntroduced: []string{},
+			wantFixed:      []string{},
+			wantUnchanged:  []string{"CVE-1"},
+		},
+		{
+			name:           "same vulnerability in another component is introduced",
+			current:        []dtrack.Finding{finding("CVE-1", "libssl", "3.0.1", 1)},
+			baseline:       []dtrack.Finding{finding("CVE-1", "openssl", "3.0.1", 1)},
+			wantIntroduced: []string{"CVE-1"},
+			wantFixed:      []string{"CVE-1"},
+			wantUnchanged:  []string{},
+		},
+		{
+			name: "sorted by severity then vulnerability",
+			current: []dtrack.Finding{
+				finding("CVE-9", "a", "1", 3),
+				finding("CVE-5", "b", "1", 0),
+				finding("CVE-2", "c", "1", 3),
+				finding("CVE-7", "d", "1", 1),
+			},
+			wantIntroduced: []string{"CVE-5", "CVE-7", "CVE-2", "CVE-9"},
+			wantFixed:      []string{},
+			wantUnchanged:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Compare(tt.current, tt.baseline)
+			if got := vulnIDs(d.Introduced); !slices.Equal(got, tt.wantIntroduced) {
+				t.Errorf("introduced = %v, want %v", got, tt.wantIntroduced)
+			}
+			if got := vulnIDs(d.Fixed); !slices.Equal(got, tt.wantFixed) {
+				t.Errorf("fixed = %v, want %v", got, tt.wantFixed)
+			}
+			if got := vulnIDs(d.Unchanged); !slices.Equal(got, tt.wantUnchanged) {
+				t.Errorf("unchanged = %v, want %v", got, tt.wantUnchanged)
+			}
+		})
+	}
+}
+
+func TestCompareViolations(t *testing.T) {
+	license := &dtrack.PolicyCondition{UUID: uuid.MustParse("6a5f4a4e-0bb4-4b8d-9d3a-1c1e9f0b5a01")}
+	security := &dtrack.PolicyCondition{UUID: uuid.MustParse("6a5f4a4e-0bb4-4b8d-9d3a-1c1e9f0b5a02")}
+	violation := func(kind string, condition *dtrack.PolicyCondition, component string) dtrack.PolicyViolation {
+		return dtrack.PolicyViolation{Type: kind, PolicyCondition: condition, Component: dtrack.Component{Name: component}}
+	}
+
+	d := CompareViolations(
+		[]dtrack.PolicyViolation{violation("LICENSE", license, "gpl-lib"), violation("SECURITY", security, "openssl")},
+		[]dtrack.PolicyViolation{violation("SECURITY", security, "openssl"), violation("LICENSE", license, "agpl-lib")},
+	)
+	if len(d.Introduced) != 1 || d.Introduced[0].Component.Name != "gpl-lib" {
+		t.Errorf("introduced = %+v, want the gpl-lib violation", d.Introduced)
+	}
+	if len(d.Unchanged) != 1 || d.Unchanged[0].Component.Name != "openssl" {
+		t.Errorf("unchanged = %+v, want the openssl violation", d.Unchanged)
+	}
+	if len(d.Fixed) != 1 || d.Fixed[0].Component.Name != "agpl-lib" {
+		t.Errorf("fixed = %+v, want the agpl-lib violation", d.Fixed)
+	}
+}
+
+func TestGateEvaluate(t *testing.T) {
+	diff := Diff{
+		Introduced: []dtrack.Finding{finding("CVE-1", "a", "1", 2)},
+		Unchanged:  []dtrack.Finding{finding("CVE-2", "b", "1", 0), finding("CVE-3", "c", "1", 1)},
+		Fixed:      []dtrack.Finding{finding("CVE-4", "d", "1", 0)},
+	}
+	tests := []struct {
+		name    string
+		gate    Gate
+		diff    Diff
+		wantErr string
+	}{
+		{
+			name: "disabled",
+			gate: Gate{},
+			diff: diff,
+		},
+		{
+			name:    "open vulnerabilities at or above the threshold",
+			gate:    Gate{Severity: "HIGH"},
+			diff:    diff,
+			wantErr: "2 open vulnerabilities with severity high or higher",
+		},
+		{
+			name:    "introduced vulnerabilities only",
+			gate:    Gate{Severity: "medium", IntroducedOnly: true},
+			diff:    diff,
+			wantErr: "1 introduced vulnerabilities with severity medium or higher",
+		},
+		{
+			name: "introduced vulnerabilities below the threshold",
+			gate: Gate{Severity: "high", IntroducedOnly: true},
+			diff: diff,
+		},
+		{
+			name: "fixed vulnerabilities are ignored",
+			gate: Gate{Severity: "critical"},
+			diff: Diff{Fixed: diff.Fixed},
+		},
+		{
+			name: "no findings, e.g. a missing baseline with nothing found",
+			gate: Gate{Severity: "low"},
+			diff: Compare(nil, nil),
+		},
+		{
+			name:    "invalid severity",
+			gate:    Gate{Severity: "urgent"},
+			diff:    diff,
+			wantErr: "invalid severity: urgent",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.gate.Evaluate(tt.diff)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Evaluate() error = %v, want none", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Errorf("Evaluate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGateEvaluateKeepsDiff(t *testing.T) {
+	introduced := make([]dtrack.Finding, 1, 2)
+	introduced[0] = finding("CVE-1", "a", "1", 0)
+	d := Diff{Introduced: introduced, Unchanged: []dtrack.Finding{finding("CVE-2", "b", "1", 0)}}
+
+	_ = Gate{Severity: "critical"}.Evaluate(d)
+	if got := introduced[:2][1].Vulnerability.VulnID; got != "" {
+		t.Errorf("Evaluate() appended %s to the introduced findings", got)
+	}
+}
+
+func TestGateValidate(t *testing.T) {
+	for severity, wantErr := range map[string]bool{"": false, "Critical": false, "unassigned": false, "severe": true} {
+		err := Gate{Severity: severity}.Validate()
+		if (err != nil) != wantErr {
+			t.Errorf("Validate(%q) error = %v, want error %v", severity, err, wantErr)
+		}
+	}
+}
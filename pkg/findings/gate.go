@@ -0,0 +1,76 @@
+package findings
+
+import (
+	"fmt"
+	"strings"
+)
+
+// severityRanks maps DependencyTrack severities to their rank, 0 being the most severe.
+var severityRanks = map[string]int{
+	"critical":   0,
+	"high":       1,
+	"medium":     2,
+	"low":        3,
+	"info":       4,
+	"unassigned": 5,
+}
+
+// ParseSeverity returns the DependencyTrack rank of a severity name.
+func ParseSeverity(s string) (int, error) {
+	rank, ok := severityRanks[strings.ToLower(s)]
+	if !ok {
+		return 0, fmt.Errorf("invalid severity: %s", s)
+	}
+	return rank, nil
+}
+
+// Gate fails a pipeline when vulnerabilities reach a severity threshold.
+type Gate struct {
+	// Severity is the lowest severity that fails the gate. An empty Severity disables the gate.
+	Severity string
+	// IntroducedOnly restricts the gate to the vulnerabilities introduced since the baseline.
+	IntroducedOnly bool
+}
+
+// Enabled reports whether the gate has a threshold.
+func (g Gate) Enabled() bool {
+	return g.Severity != ""
+}
+
+// Validate checks the gate severity without evaluating the gate.
+func (g Gate) Validate() error {
+	if !g.Enabled() {
+		return nil
+	}
+	_, err := ParseSeverity(g.Severity)
+	return err
+}
+
+// Evaluate returns an error when the diff holds vulnerabilities at or above the gate severity.
+func (g Gate) Evaluate(d Diff) error {
+	if !g.Enabled() {
+		return nil
+	}
+	threshold, err := ParseSeverity(g.Severity)
+	if err != nil {
+		return err
+	}
+
+	candidates := d.Introduced
+	scope := "introduced"
+	if !g.IntroducedOnly {
+		candidates = append(candidates[:len(candidates):len(candidates)], d.Unchanged...)
+		scope = "open"
+	}
+
+	count := 0
+	for _, f := range candidates {
+		if f.Vulnerability.SeverityRank <= threshold {
+			count++
+		}
+	}
+	if count > 0 {
+		return fmt.Errorf("%d %s vulnerabilities with severity %s or higher", count, scope, strings.ToLower(g.Severity))
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Package gitlab implements the small subset of the GitLab REST API used to comment on merge requests.
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client talks to the GitLab REST API v4 on behalf of one project.
+type Client struct {
+	baseURL    string
+	projectID  string
+	token      string
+	httpClient *http.Client
+}
+
+// Note is a GitLab merge request note (comment).
+type Note struct {
+	ID   int    `json:"id"`
+	Body string `json:"body"`
+}
+
+// APIError is returned when GitLab answers with a non 2xx status.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("gitlab api error (status: %d): %s", e.StatusCode, e.Message)
+}
+
+// NewClient returns a client for the API at baseURL (typically CI_API_V4_URL) acting on projectID (CI_PROJECT_ID).
+func NewClient(baseURL string, projectID string, token string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		projectID:  projectID,
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// MergeRequestNotes returns every note of a merge request.
+func (c *Client) MergeRequestNotes(ctx context.Context, mrIID string) ([]Note, error) {
+	var notes []Note
+	page := "1"
+	for page != "" {
+		var pageNotes []Note
+		res, err := c.do(ctx, http.MethodGet, c.notesPath(mrIID)+"?per_page=100&page="+page, nil, &pageNotes)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, pageNotes...)
+		page = res.Header.Get("X-Next-Page")
+	}
+	return notes, nil
+}
+
+// CreateMergeRequestNote adds a new note to a merge request.
+func (c *Client) CreateMergeRequestNote(ctx context.Context, mrIID string, body string) (Note, error) {
+	var note Note
+	_, err := c.do(ctx, http.MethodPost, c.notesPath(mrIID), map[string]string{"body": body}, &note)
+	return note, err
+}
+
+// UpdateMergeRequestNote replaces the body of an existing merge request note.
+func (c *Client) UpdateMergeRequestNote(ctx context.Context, mrIID string, noteID int, body string) (Note, error) {
+	var note Note
+	_, err := c.do(ctx, http.MethodPut, fmt.Sprintf("%s/%d", c.notesPath(mrIID), noteID), map[string]string{"body": body}, &note)
+	return note, err
+}
+
+// UpsertMergeRequestNote updates the first note containing marker, or creates a new note when there is none.
+// The marker is prepended to body so that the next call finds the note again. It returns whether a note was created.
+func (c *Client) UpsertMergeRequestNote(ctx context.Context, mrIID string, marker string, body string) (Note, bool, error) {
+	notes, err := c.MergeRequestNotes(ctx, mrIID)
+	if err != nil {
+		return Note{}, false, fmt.Errorf("failed to list merge request notes: %w", err)
+	}
+
+	body = marker + "\n" + body
+	for _, n := range notes {
+		if strings.Contains(n.Body, marker) {
+			note, err := c.UpdateMergeRequestNote(ctx, mrIID, n.ID, body)
+			if err != nil {
+				return Note{}, false, fmt.Errorf("failed to update merge request note %d: %w", n.ID, err)
+			}
+			return note, false, nil
+		}
+	}
+
+	note, err := c.CreateMergeRequestNote(ctx, mrIID, body)
+	if err != nil {
+		return Note{}, false, fmt.Errorf("failed to create merge request note: %w", err)
+	}
+	return note, true, nil
+}
+
+func (c *Client) notesPath(mrIID string) string {
+	return fmt.Sprintf("/projects/%s/merge_requests/%s/notes", url.PathEscape(c.projectID), url.PathEscape(mrIID))
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, body any, v any) (*http.Response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return res, &APIError{StatusCode: res.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+
+	if v != nil {
+		if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+			return res, fmt.Errorf("failed to decode gitlab response: %w", err)
+		}
+	}
+	return res, nil
+}
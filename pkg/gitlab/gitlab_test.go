@@ -0,0 +1,263 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testProjectID = "group/project"
+	testMRIID     = "42"
+	testToken     = "glpat-secret"
+	testMarker    = `<!-- trivy-plugin-dependencytrack project="app" -->`
+)
+
+// fakeGitLab serves the merge request notes endpoints, paginated with perPage notes per page.
+type fakeGitLab struct {
+	t       *testing.T
+	perPage int
+
+	mu     sync.Mutex
+	notes  []Note
+	nextID int
+	tokens []string
+	posts  int
+	puts   map[int]int
+}
+
+func newFakeGitLab(t *testing.T, perPage int, notes ...Note) (*fakeGitLab, *httptest.Server) {
+	f := &fakeGitLab{t: t, perPage: perPage, notes: notes, nextID: 1000, puts: map[int]int{}}
+	mux := http.NewServeMux()
+	path := "/api/v4/projects/" + strings.ReplaceAll(testProjectID, "/", "%2F") + "/merge_requests/" + testMRIID + "/notes"
+	mux.HandleFunc("GET "+path, f.list)
+	mux.HandleFunc("POST "+path, f.create)
+	mux.HandleFunc("PUT "+path+"/{id}", f.update)
+	server := httptest.NewServer(f.withToken(mux))
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeGitLab) withToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.tokens = append(f.tokens, r.Header.Get("PRIVATE-TOKEN"))
+		f.mu.Unlock()
+		if r.Header.Get("PRIVATE-TOKEN") != testToken {
+			http.Error(w, `{"message":"401 Unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (f *fakeGitLab) list(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	start := min((page-1)*f.perPage, len(f.notes))
+	end := min(start+f.perPage, len(f.notes))
+	if end < len(f.notes) {
+		w.Header().Set("X-Next-Page", strconv.Itoa(page+1))
+	}
+	writeJSON(w, http.StatusOK, f.notes[start:end])
+}
+
+func (f *fakeGitLab) create(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var req struct{ Body string }
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.posts++
+	f.nextID++
+	note := Note{ID: f.nextID, Body: req.Body}
+	f.notes = append(f.notes, note)
+	writeJSON(w, http.StatusCreated, note)
+}
+
+func (f *fakeGitLab) update(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	var req struct{ Body string }
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for i, n := range f.notes {
+		if n.ID == id {
+			f.puts[id]++
+			f.notes[i].Body = req.Body
+			writeJSON(w, http.StatusOK, f.notes[i])
+			return
+		}
+	}
+	http.Error(w, `{"message":"404 Not found"}`, http.StatusNotFound)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func otherNotes(n int) []Note {
+	notes := make([]Note, n)
+	for i := range notes {
+		notes[i] = Note{ID: i + 1, Body: fmt.Sprintf("LGTM %d", i+1)}
+	}
+	return notes
+}
+
+func TestUpsertMergeRequestNote(t *testing.T) {
+	tests := []struct {
+		name        string
+		perPage     int
+		notes       []Note
+		wantCreated bool
+		wantID      int
+	}{
+		{
+			name:        "creates a note when none has the marker",
+			perPage:     100,
+			notes:       otherNotes(3),
+			wantCreated: true,
+			wantID:      1001,
+		},
+		{
+			name:    "updates the note with the marker",
+			perPage: 100,
+			notes: append(otherNotes(2),
+				Note{ID: 7, Body: `<!-- trivy-plugin-dependencytrack project="other" -->` + "\nold"},
+				Note{ID: 8, Body: testMarker + "\nold"}),
+			wantID: 8,
+		},
+		{
+			name:    "finds the marker on the second page",
+			perPage: 2,
+			notes:   append(otherNotes(2), Note{ID: 9, Body: testMarker + "\nold"}),
+			wantID:  9,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, server := newFakeGitLab(t, tt.perPage, tt.notes...)
+			client := NewClient(server.URL+"/api/v4/", testProjectID, testToken)
+
+			note, created, err := client.UpsertMergeRequestNote(context.Background(), testMRIID, testMarker, "new summary")
+			if err != nil {
+				t.Fatalf("UpsertMergeRequestNote() error = %v", err)
+			}
+			if created != tt.wantCreated {
+				t.Errorf("created = %v, want %v", created, tt.wantCreated)
+			}
+			if note.ID != tt.wantID {
+				t.Errorf("note ID = %d, want %d", note.ID, tt.wantID)
+			}
+			if want := testMarker + "\nnew summary"; note.Body != want {
+				t.Errorf("note body = %q, want %q", note.Body, want)
+			}
+
+			wantPosts, wantPuts := 0, 1
+			if tt.wantCreated {
+				wantPosts, wantPuts = 1, 0
+			}
+			if fake.posts != wantPosts {
+				t.Errorf("created %d notes, want %d", fake.posts, wantPosts)
+			}
+			if fake.puts[tt.wantID] != wantPuts || len(fake.puts) != wantPuts {
+				t.Errorf("updated notes %v, want %d update of note %d", fake.puts, wantPuts, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestUpsertMergeRequestNoteSendsToken(t *testing.T) {
+	fake, server := newFakeGitLab(t, 1, otherNotes(2)...)
+	client := NewClient(server.URL+"/api/v4", testProjectID, testToken)
+
+	_, _, err := client.UpsertMergeRequestNote(context.Background(), testMRIID, testMarker, "summary")
+	if err != nil {
+		t.Fatalf("UpsertMergeRequestNote() error = %v", err)
+	}
+	// Two pages of notes, then the creation.
+	if len(fake.tokens) != 3 {
+		t.Fatalf("got %d requests, want 3", len(fake.tokens))
+	}
+	for i, token := range fake.tokens {
+		if token != testToken {
+			t.Errorf("request %d PRIVATE-TOKEN = %q, want %q", i, token, testToken)
+		}
+	}
+}
+
+func TestUpsertMergeRequestNoteError(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		handler    http.HandlerFunc
+		wantStatus int
+	}{
+		{
+			name:       "rejected token",
+			token:      "wrong",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:  "failed creation",
+			token: testToken,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					writeJSON(w, http.StatusOK, []Note{})
+					return
+				}
+				http.Error(w, `{"message":"403 Forbidden"}`, http.StatusForbidden)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:  "failed listing",
+			token: testToken,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "bad gateway", http.StatusBadGateway)
+			},
+			wantStatus: http.StatusBadGateway,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := ""
+			if tt.handler != nil {
+				server := httptest.NewServer(tt.handler)
+				t.Cleanup(server.Close)
+				url = server.URL
+			} else {
+				_, server := newFakeGitLab(t, 100)
+				url = server.URL + "/api/v4"
+			}
+			client := NewClient(url, testProjectID, tt.token)
+
+			_, _, err := client.UpsertMergeRequestNote(context.Background(), testMRIID, testMarker, "summary")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("UpsertMergeRequestNote() error = %v, want an *APIError", err)
+			}
+			if apiErr.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", apiErr.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
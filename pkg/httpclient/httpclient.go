@@ -0,0 +1,140 @@
+// Package httpclient builds the HTTP client shared by the commands to reach DependencyTrack.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/config"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// DefaultTimeout bounds each request when no timeout is set, as the DependencyTrack client does.
+const DefaultTimeout = 10 * time.Second
+
+// UserAgent identifies the plugin and its version to DependencyTrack and the proxies in between.
+func UserAgent(version string) string {
+	return "trivy-plugin-dependencytrack/" + version
+}
+
+// New builds an HTTP client with the TLS and HTTP settings, sending the user agent and the extra headers on every
+// request.
+func New(tlsSettings config.TLS, settings config.HTTP, userAgent string) (*http.Client, error) {
+	transport, err := NewTransport(tlsSettings)
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = Proxy(settings)
+
+	header := http.Header{}
+	header.Set("User-Agent", userAgent)
+	for _, h := range settings.Headers {
+		name, value, err := config.ParseHeader(h)
+		if err != nil {
+			return nil, err
+		}
+		header.Add(name, value)
+	}
+
+	timeout := settings.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	return &http.Client{Timeout: timeout, Transport: WithHeader(transport, header)}, nil
+}
+
+// Proxy selects the proxy of a request from the proxy settings, falling back to the proxy environment variables.
+func Proxy(settings config.HTTP) func(*http.Request) (*url.URL, error) {
+	if settings.Proxy == "" && settings.NoProxy == "" {
+		return http.ProxyFromEnvironment
+	}
+	proxy := httpproxy.FromEnvironment()
+	if settings.Proxy != "" {
+		proxy.HTTPProxy = settings.Proxy
+		proxy.HTTPSProxy = settings.Proxy
+	}
+	if settings.NoProxy != "" {
+		proxy.NoProxy = settings.NoProxy
+	}
+	proxyFunc := proxy.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+// WithHeader sets the header on every request sent through base, replacing the values set by the caller.
+func WithHeader(base http.RoundTripper, header http.Header) http.RoundTripper {
+	return headerTransport{base: base, header: header}
+}
+
+type headerTransport struct {
+	base   http.RoundTripper
+	header http.Header
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	clone := req.Clone(req.Context())
+	for name, values := range t.header {
+		clone.Header[name] = values
+	}
+	return t.base.RoundTrip(clone)
+}
+
+// NewTransport returns a copy of the default transport, so that the proxy environment variables still apply, with the
+// given TLS settings.
+func NewTransport(settings config.TLS) (*http.Transport, error) {
+	tlsConfig, err := NewTLSConfig(settings)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// NewTLSConfig trusts the system CAs plus the CA certificate of the settings, and presents the client certificate if
+// any.
+func NewTLSConfig(settings config.TLS) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Explicitly requested with --insecure-skip-verify, the commands warn about it.
+		InsecureSkipVerify: settings.InsecureSkipVerify, //nolint:gosec
+	}
+	if settings.MinVersion != "" {
+		version, ok := config.TLSVersions[settings.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("invalid tls-min-version %q", settings.MinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if settings.CACert != "" {
+		pem, err := os.ReadFile(settings.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca-cert: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificate found in ca-cert %s", settings.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if settings.ClientCert != "" || settings.ClientKey != "" {
+		certificate, err := tls.LoadX509KeyPair(settings.ClientCert, settings.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client-cert and client-key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return tlsConfig, nil
+}
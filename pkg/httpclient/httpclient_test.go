@@ -0,0 +1,263 @@
+package httpclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/config"
+)
+
+// testCA signs the server and client certificates of the tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue signs a certificate for the usage, valid for 127.0.0.1, and returns it with its PEM encoded certificate and
+// key.
+func (ca testCA) issue(t *testing.T, usage x509.ExtKeyUsage) (tls.Certificate, []byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certificate, certPEM, keyPEM
+}
+
+func writeFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewTLS(t *testing.T) {
+	ca := newTestCA(t, "test CA")
+	unknownCA := newTestCA(t, "unknown CA")
+	serverCert, _, _ := ca.issue(t, x509.ExtKeyUsageServerAuth)
+	_, clientCertPEM, clientKeyPEM := ca.issue(t, x509.ExtKeyUsageClientAuth)
+
+	caFile := writeFile(t, "ca.pem", ca.pem)
+	unknownCAFile := writeFile(t, "unknown-ca.pem", unknownCA.pem)
+	clientCertFile := writeFile(t, "client.pem", clientCertPEM)
+	clientKeyFile := writeFile(t, "client-key.pem", clientKeyPEM)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	tests := []struct {
+		name     string
+		server   *tls.Config
+		settings config.TLS
+		wantErr  string
+	}{
+		{
+			name:     "custom ca accepted",
+			server:   &tls.Config{Certificates: []tls.Certificate{serverCert}},
+			settings: config.TLS{CACert: caFile},
+		},
+		{
+			name:     "unknown ca rejected",
+			server:   &tls.Config{Certificates: []tls.Certificate{serverCert}},
+			settings: config.TLS{CACert: unknownCAFile},
+			wantErr:  "certificate signed by unknown authority",
+		},
+		{
+			name:     "system cas only rejected",
+			server:   &tls.Config{Certificates: []tls.Certificate{serverCert}},
+			settings: config.TLS{},
+			wantErr:  "certificate signed by unknown authority",
+		},
+		{
+			name: "client certificate required and missing",
+			server: &tls.Config{
+				Certificates: []tls.Certificate{serverCert},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    clientCAs,
+			},
+			settings: config.TLS{CACert: caFile},
+			wantErr:  "certificate required",
+		},
+		{
+			name: "client certificate presented",
+			server: &tls.Config{
+				Certificates: []tls.Certificate{serverCert},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    clientCAs,
+			},
+			settings: config.TLS{CACert: caFile, ClientCert: clientCertFile, ClientKey: clientKeyFile},
+		},
+		{
+			name: "tls 1.2 peer rejected with minimum 1.3",
+			server: &tls.Config{
+				Certificates: []tls.Certificate{serverCert},
+				MaxVersion:   tls.VersionTLS12,
+			},
+			settings: config.TLS{CACert: caFile, MinVersion: "1.3"},
+			wantErr:  "protocol version",
+		},
+		{
+			name: "tls 1.2 peer accepted by default",
+			server: &tls.Config{
+				Certificates: []tls.Certificate{serverCert},
+				MaxVersion:   tls.VersionTLS12,
+			},
+			settings: config.TLS{CACert: caFile},
+		},
+		{
+			name:     "insecure skip verify",
+			server:   &tls.Config{Certificates: []tls.Certificate{serverCert}},
+			settings: config.TLS{InsecureSkipVerify: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			server.TLS = tt.server
+			// The handshake errors of the rejected clients are expected.
+			server.Config.ErrorLog = log.New(io.Discard, "", 0)
+			server.StartTLS()
+			defer server.Close()
+
+			client, err := New(tt.settings, config.HTTP{}, "test")
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			resp, err := client.Get(server.URL)
+			if tt.wantErr != "" {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatalf("Get() status = %d, want an error containing %q", resp.StatusCode, tt.wantErr)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Get() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusNoContent {
+				t.Errorf("Get() status = %d, want %d", resp.StatusCode, http.StatusNoContent)
+			}
+		})
+	}
+}
+
+func TestNewTLSConfigErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings config.TLS
+		wantErr  string
+	}{
+		{name: "missing ca-cert", settings: config.TLS{CACert: filepath.Join(t.TempDir(), "missing.pem")}, wantErr: "failed to read ca-cert"},
+		{name: "ca-cert without pem", settings: config.TLS{CACert: writeFile(t, "ca.pem", []byte("not a certificate"))}, wantErr: "no PEM certificate found"},
+		{name: "client-cert without client-key", settings: config.TLS{ClientCert: writeFile(t, "client.pem", nil)}, wantErr: "failed to load client-cert and client-key"},
+		{name: "unknown min version", settings: config.TLS{MinVersion: "1.1"}, wantErr: `invalid tls-min-version "1.1"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTLSConfig(tt.settings)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewTLSConfig() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewHeaders(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	client, err := New(config.TLS{}, config.HTTP{Headers: []string{"X-Tenant = acme", "X-Api-Key=s3cr3t"}}, "trivy-plugin-dependencytrack/test")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("X-Tenant", "overridden")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	for name, want := range map[string]string{"User-Agent": "trivy-plugin-dependencytrack/test", "X-Tenant": "acme", "X-Api-Key": "s3cr3t"} {
+		if got.Get(name) != want {
+			t.Errorf("header %s = %q, want %q", name, got.Get(name), want)
+		}
+	}
+}
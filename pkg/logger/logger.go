@@ -194,7 +194,7 @@ func New(cfg Config) (*slog.Logger, error) {
 		return nil, fmt.Errorf("unsupported log format: %s", cfg.Format)
 	}
 
-	return slog.New(handler), nil
+	return slog.New(&redactHandler{next: handler}), nil
 }
 
 // ctxKey provides a location to store a logger in a context.
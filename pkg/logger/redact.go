@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces secrets in log records.
+const Redacted = "[REDACTED]"
+
+// minSecretLength avoids redacting common words when a secret is set to a short placeholder.
+const minSecretLength = 6
+
+// secretAttrKeys are attribute keys whose string values are always redacted.
+var secretAttrKeys = []string{"apikey", "api-key", "apiKey", "password", "token", "bearer-token", "bearerToken"}
+
+var secrets struct {
+	sync.RWMutex
+	values []string
+}
+
+// RedactSecret registers a secret so that every logger of this package replaces it in messages and attributes. Empty
+// and very short values are ignored.
+func RedactSecret(value string) {
+	if len(value) < minSecretLength {
+		return
+	}
+	secrets.Lock()
+	defer secrets.Unlock()
+	if !slices.Contains(secrets.values, value) {
+		secrets.values = append(secrets.values, value)
+	}
+}
+
+func redact(s string) string {
+	secrets.RLock()
+	defer secrets.RUnlock()
+	for _, v := range secrets.values {
+		s = strings.ReplaceAll(s, v, Redacted)
+	}
+	return s
+}
+
+// redactHandler removes the registered secrets from the records before passing them to the wrapped handler.
+type redactHandler struct {
+	next slog.Handler
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		redacted = append(redacted, redactAttr(a))
+	}
+	return &redactHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		if slices.Contains(secretAttrKeys, a.Key) && v.String() != "" {
+			return slog.String(a.Key, Redacted)
+		}
+		return slog.String(a.Key, redact(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]any, 0, len(group))
+		for _, g := range group {
+			redacted = append(redacted, redactAttr(g))
+		}
+		return slog.Group(a.Key, redacted...)
+	case slog.KindAny:
+		// Errors and other values are printed from their text, which may embed a secret.
+		s := fmt.Sprint(v.Any())
+		if r := redact(s); r != s {
+			return slog.String(a.Key, r)
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedactHandler(t *testing.T) {
+	const secret = "odt_s3cr3t-value"
+	RedactSecret(secret)
+	RedactSecret("short")
+
+	tests := []struct {
+		name string
+		log  func(l *slog.Logger)
+		want []string
+	}{
+		{
+			name: "message",
+			log:  func(l *slog.Logger) { l.Info("calling with key " + secret) },
+			want: []string{`"msg":"calling with key [REDACTED]"`},
+		},
+		{
+			name: "string attribute",
+			log:  func(l *slog.Logger) { l.Info("request", "url", "https://dt.example.com/?key="+secret) },
+			want: []string{`"url":"https://dt.example.com/?key=[REDACTED]"`},
+		},
+		{
+			name: "error attribute",
+			log:  func(l *slog.Logger) { l.Error("failed", "error", errors.New("header X-Api-Key: "+secret+" rejected")) },
+			want: []string{`"error":"header X-Api-Key: [REDACTED] rejected"`},
+		},
+		{
+			name: "nested groups",
+			log: func(l *slog.Logger) {
+				l.Info("config", slog.Group("server", slog.String("url", "https://dt.example.com"),
+					slog.Group("auth", slog.String("header", "Bearer "+secret))))
+			},
+			want: []string{`"server":{"url":"https://dt.example.com","auth":{"header":"Bearer [REDACTED]"}}`},
+		},
+		{
+			name: "logger attributes and group",
+			log:  func(l *slog.Logger) { l.With("token-source", secret).WithGroup("upload").Info("done", "key", secret) },
+			want: []string{`"token-source":"[REDACTED]"`, `"upload":{"key":"[REDACTED]"}`},
+		},
+		{
+			name: "secret attribute key with an unregistered value",
+			log:  func(l *slog.Logger) { l.Info("login", "password", "not-registered", "user", "admin") },
+			want: []string{`"password":"[REDACTED]"`, `"user":"admin"`},
+		},
+		{
+			name: "short values are not registered",
+			log:  func(l *slog.Logger) { l.Info("a short message") },
+			want: []string{`"msg":"a short message"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.log(slog.New(&redactHandler{next: slog.NewJSONHandler(&buf, nil)}))
+
+			got := buf.String()
+			if strings.Contains(got, secret) {
+				t.Errorf("log leaks the secret: %s", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("log = %s, want it to contain %s", got, want)
+				}
+			}
+		})
+	}
+}
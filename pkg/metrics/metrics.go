@@ -0,0 +1,189 @@
+// Package metrics renders the metrics DependencyTrack computes for a project.
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+// Output formats supported by Write and WriteHistory. FormatSparkline only applies to a history.
+const (
+	FormatTable     = "table"
+	FormatSparkline = "sparkline"
+	FormatJSON      = "json"
+)
+
+// Current are the latest metrics of a project.
+type Current struct {
+	ProjectUUID    string                `json:"projectUuid"`
+	ProjectName    string                `json:"projectName"`
+	ProjectVersion string                `json:"projectVersion"`
+	Metrics        dtrack.ProjectMetrics `json:"metrics"`
+}
+
+// History are the metrics of a project since a date, oldest first.
+type History struct {
+	ProjectUUID    string                  `json:"projectUuid"`
+	ProjectName    string                  `json:"projectName"`
+	ProjectVersion string                  `json:"projectVersion"`
+	Since          time.Time               `json:"since"`
+	Metrics        []dtrack.ProjectMetrics `json:"metrics"`
+}
+
+// ParseSince reads a date (YYYY-MM-DD), a number of days such as "30d", or a Go duration such as "72h", the last two
+// counted back from now.
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n > 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid since %q, expected a date (YYYY-MM-DD), a number of days (30d) or a duration (72h)", s)
+}
+
+// Write renders the current metrics to w in the given format.
+func Write(w io.Writer, format string, c Current) error {
+	switch format {
+	case FormatTable, "":
+		return Table(w, c)
+	case FormatJSON:
+		return writeJSON(w, c)
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// WriteHistory renders a metrics history to w in the given format.
+func WriteHistory(w io.Writer, format string, h History) error {
+	if h.Metrics == nil {
+		h.Metrics = []dtrack.ProjectMetrics{}
+	}
+	switch format {
+	case FormatTable, "":
+		return HistoryTable(w, h)
+	case FormatSparkline:
+		return Sparklines(w, h)
+	case FormatJSON:
+		return writeJSON(w, h)
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// Table renders the current metrics as a short report.
+func Table(w io.Writer, c Current) error {
+	m := c.Metrics
+	fmt.Fprintf(w, "Project %s @ %s (%s)\n", c.ProjectName, c.ProjectVersion, c.ProjectUUID)
+	fmt.Fprintf(w, "Last updated: %s\n\n", formatTimestamp(m.LastOccurrence))
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Vulnerabilities:\t%d critical, %d high, %d medium, %d low, %d unassigned\n", m.Critical, m.High, m.Medium,
+		m.Low, m.Unassigned)
+	fmt.Fprintf(tw, "Vulnerable components:\t%d of %d (%d suppressed findings)\n", m.VulnerableComponents, m.Components, m.Suppressed)
+	fmt.Fprintf(tw, "Inherited risk score:\t%g\n", m.InheritedRiskScore)
+	fmt.Fprintf(tw, "Audit progress:\t%d of %d findings audited (%s)\n", m.FindingsAudited, m.FindingsTotal,
+		percent(m.FindingsAudited, m.FindingsTotal))
+	fmt.Fprintf(tw, "Policy violations:\t%d (%d fail, %d warn, %d info)\n", m.PolicyViolationsTotal, m.PolicyViolationsFail,
+		m.PolicyViolationsWarn, m.PolicyViolationsInfo)
+	fmt.Fprintf(tw, "Violation audit progress:\t%d of %d violations audited (%s)\n", m.PolicyViolationsAudited,
+		m.PolicyViolationsTotal, percent(m.PolicyViolationsAudited, m.PolicyViolationsTotal))
+	return tw.Flush()
+}
+
+// HistoryTable renders one line per metrics snapshot.
+func HistoryTable(w io.Writer, h History) error {
+	fmt.Fprintf(w, "Project %s @ %s since %s\n\n", h.ProjectName, h.ProjectVersion, h.Since.Format(time.DateOnly))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DATE\tCRITICAL\tHIGH\tMEDIUM\tLOW\tUNASSIGNED\tRISK SCORE\tAUDITED\tVIOLATIONS")
+	for _, m := range h.Metrics {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%g\t%d/%d\t%d\n", formatTimestamp(m.LastOccurrence), m.Critical, m.High,
+			m.Medium, m.Low, m.Unassigned, m.InheritedRiskScore, m.FindingsAudited, m.FindingsTotal, m.PolicyViolationsTotal)
+	}
+	return tw.Flush()
+}
+
+// Sparklines renders the trend of each series on one line, followed by its first and last values.
+func Sparklines(w io.Writer, h History) error {
+	fmt.Fprintf(w, "Project %s @ %s since %s (%d snapshots)\n\n", h.ProjectName, h.ProjectVersion,
+		h.Since.Format(time.DateOnly), len(h.Metrics))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, s := range []struct {
+		name  string
+		value func(dtrack.ProjectMetrics) float64
+	}{
+		{"critical", func(m dtrack.ProjectMetrics) float64 { return float64(m.Critical) }},
+		{"high", func(m dtrack.ProjectMetrics) float64 { return float64(m.High) }},
+		{"medium", func(m dtrack.ProjectMetrics) float64 { return float64(m.Medium) }},
+		{"low", func(m dtrack.ProjectMetrics) float64 { return float64(m.Low) }},
+		{"risk score", func(m dtrack.ProjectMetrics) float64 { return m.InheritedRiskScore }},
+		{"unaudited", func(m dtrack.ProjectMetrics) float64 { return float64(m.FindingsUnaudited) }},
+		{"violations", func(m dtrack.ProjectMetrics) float64 { return float64(m.PolicyViolationsTotal) }},
+	} {
+		values := make([]float64, len(h.Metrics))
+		for i, m := range h.Metrics {
+			values[i] = s.value(m)
+		}
+		trend := "-"
+		if len(values) > 0 {
+			trend = fmt.Sprintf("%g -> %g", values[0], values[len(values)-1])
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.name, Sparkline(values), trend)
+	}
+	return tw.Flush()
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values with block characters scaled between their minimum and maximum.
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if hi > lo {
+			i = int((v - lo) / (hi - lo) * float64(len(sparks)-1))
+		}
+		b.WriteRune(sparks[i])
+	}
+	return b.String()
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func formatTimestamp(ms int) string {
+	if ms == 0 {
+		return "never"
+	}
+	return time.UnixMilli(int64(ms)).UTC().Format(time.DateTime)
+}
+
+func percent(n int, total int) string {
+	if total == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.0f%%", float64(n)*100/float64(total))
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "2024-01-31", want: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{in: "30d", want: time.Date(2024, 2, 14, 10, 30, 0, 0, time.UTC)},
+		{in: "1d", want: time.Date(2024, 3, 14, 10, 30, 0, 0, time.UTC)},
+		{in: "72h", want: time.Date(2024, 3, 12, 10, 30, 0, 0, time.UTC)},
+		{in: "90m", want: time.Date(2024, 3, 15, 9, 0, 0, 0, time.UTC)},
+		{in: "", wantErr: true},
+		{in: "0d", wantErr: true},
+		{in: "-7d", wantErr: true},
+		{in: "-1h", wantErr: true},
+		{in: "d", wantErr: true},
+		{in: "2w", wantErr: true},
+		{in: "2024-02-30", wantErr: true},
+		{in: "15/03/2024", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseSince(tt.in, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseSince() = %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSince() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseSince() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   string
+	}{
+		{name: "no values", values: nil, want: ""},
+		{name: "single value", values: []float64{42}, want: "▁"},
+		{name: "all zero", values: []float64{0, 0, 0}, want: "▁▁▁"},
+		{name: "constant", values: []float64{5, 5}, want: "▁▁"},
+		{name: "every level", values: []float64{0, 1, 2, 3, 4, 5, 6, 7}, want: "▁▂▃▄▅▆▇█"},
+		{name: "scaled between min and max", values: []float64{10, 20, 15, 10}, want: "▁█▄▁"},
+		{name: "fractions", values: []float64{0.5, 0.25, 0}, want: "█▄▁"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sparkline(tt.values); got != tt.want {
+				t.Errorf("Sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package naming
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// shortDigestLength is the number of hex characters kept from an image digest, as in `docker images`.
+const shortDigestLength = 12
+
+// FromBOM derives a project name and version from the sbom component. Container images are named after their
+// repository without registry, and versioned by tag, or by short digest when the image is referenced by digest only.
+// The "latest" tag is replaced by latest when set, else by the short digest when known. Other components keep their
+// name, a repository URL being reduced to its path, and their version. Values that cannot be derived are empty.
+func FromBOM(c Component, latest string) (string, string) {
+	if c.Type == "container" {
+		return fromImage(c, latest)
+	}
+
+	name := c.Name
+	if u, err := url.Parse(name); err == nil && u.Scheme != "" && u.Host != "" {
+		name = strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	}
+	if name == "." {
+		name = ""
+	}
+	return name, c.Version
+}
+
+func fromImage(c Component, latest string) (string, string) {
+	repository, tag, digest := ParseImageRef(c.Name)
+	if digest == "" {
+		digest = purlDigest(c.PURL)
+	}
+	short := ShortDigest(digest)
+
+	version := tag
+	if version == "" {
+		version = short
+	}
+	if version == "" && c.Version != "" {
+		version = c.Version
+	}
+	if version == "latest" {
+		switch {
+		case latest != "":
+			version = latest
+		case short != "":
+			version = short
+		}
+	}
+	return repository, version
+}
+
+// ParseImageRef splits an image reference into its repository without registry, its tag and its digest, e.g.
+// "registry.example.com:5000/team/app:1.2@sha256:..." gives "team/app", "1.2" and "sha256:...". Docker Hub official
+// images drop their "library/" prefix.
+func ParseImageRef(ref string) (repository string, tag string, digest string) {
+	ref, digest, _ = strings.Cut(ref, "@")
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref, tag = ref[:i], ref[i+1:]
+	}
+
+	registry, rest, ok := strings.Cut(ref, "/")
+	if ok && (strings.ContainsAny(registry, ".:") || registry == "localhost") {
+		ref = rest
+		if registry == "docker.io" || registry == "index.docker.io" {
+			ref = strings.TrimPrefix(ref, "library/")
+		}
+	}
+	return ref, tag, digest
+}
+
+// ShortDigest keeps the first 12 hex characters of a digest, e.g. "sha256:4bcff63911fc", the length `docker images`
+// shows. Other values are returned unchanged.
+func ShortDigest(digest string) string {
+	algorithm, hex, ok := strings.Cut(digest, ":")
+	if !ok {
+		return digest
+	}
+	if len(hex) > shortDigestLength {
+		hex = hex[:shortDigestLength]
+	}
+	return algorithm + ":" + hex
+}
+
+// purlDigest reads the digest of a pkg:oci purl, e.g. pkg:oci/app@sha256%3A...?repository_url=...
+func purlDigest(purl string) string {
+	if !strings.HasPrefix(purl, "pkg:oci/") {
+		return ""
+	}
+	purl, _, _ = strings.Cut(purl, "?")
+	_, version, ok := strings.Cut(path.Base(purl), "@")
+	if !ok {
+		return ""
+	}
+	digest, err := url.PathUnescape(version)
+	if err != nil {
+		return ""
+	}
+	return digest
+}
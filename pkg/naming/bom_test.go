@@ -0,0 +1,191 @@
+package naming
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testDigest = "sha256:4bcff63911fcb4448bd4fdacec207030997caf25e9bea4045fa6c8c44de311d1"
+
+func TestParseImageRef(t *testing.T) {
+	tests := []struct {
+		ref            string
+		wantRepository string
+		wantTag        string
+		wantDigest     string
+	}{
+		{ref: "alpine", wantRepository: "alpine"},
+		{ref: "alpine:3.19", wantRepository: "alpine", wantTag: "3.19"},
+		{ref: "alpine:latest", wantRepository: "alpine", wantTag: "latest"},
+		{ref: "docker.io/library/alpine:3.19", wantRepository: "alpine", wantTag: "3.19"},
+		{ref: "index.docker.io/library/alpine", wantRepository: "alpine"},
+		{ref: "docker.io/bitnami/redis:7.2", wantRepository: "bitnami/redis", wantTag: "7.2"},
+		{ref: "team/app:1.0", wantRepository: "team/app", wantTag: "1.0"},
+		{ref: "ghcr.io/acme/app:v1.2.3", wantRepository: "acme/app", wantTag: "v1.2.3"},
+		{ref: "registry.example.com:5000/team/app", wantRepository: "team/app"},
+		{ref: "registry.example.com:5000/team/app:1.2", wantRepository: "team/app", wantTag: "1.2"},
+		{ref: "localhost/app:dev", wantRepository: "app", wantTag: "dev"},
+		{ref: "localhost:5000/app@" + testDigest, wantRepository: "app", wantDigest: testDigest},
+		{ref: "ghcr.io/acme/app@" + testDigest, wantRepository: "acme/app", wantDigest: testDigest},
+		{ref: "ghcr.io/acme/app:1.2@" + testDigest, wantRepository: "acme/app", wantTag: "1.2", wantDigest: testDigest},
+	}
+	for _, tt := range tests {
+		repository, tag, digest := ParseImageRef(tt.ref)
+		if repository != tt.wantRepository || tag != tt.wantTag || digest != tt.wantDigest {
+			t.Errorf("ParseImageRef(%q) = %q, %q, %q, want %q, %q, %q",
+				tt.ref, repository, tag, digest, tt.wantRepository, tt.wantTag, tt.wantDigest)
+		}
+	}
+}
+
+func TestShortDigest(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: testDigest, want: "sha256:4bcff63911fc"},
+		{in: "sha256:4bcff6", want: "sha256:4bcff6"},
+		{in: "4bcff63911fcb4448bd4", want: "4bcff63911fcb4448bd4"},
+		{in: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := ShortDigest(tt.in); got != tt.want {
+			t.Errorf("ShortDigest(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFromBOM(t *testing.T) {
+	ociPURL := "pkg:oci/app@sha256%3A4bcff63911fcb4448bd4fdacec207030997caf25e9bea4045fa6c8c44de311d1?repository_url=ghcr.io%2Facme%2Fapp"
+
+	tests := []struct {
+		name        string
+		component   Component
+		latest      string
+		wantName    string
+		wantVersion string
+	}{
+		{
+			name:        "image tag",
+			component:   Component{Type: "container", Name: "ghcr.io/acme/app:v1.2.3"},
+			wantName:    "acme/app",
+			wantVersion: "v1.2.3",
+		},
+		{
+			name:        "image digest only",
+			component:   Component{Type: "container", Name: "ghcr.io/acme/app@" + testDigest},
+			wantName:    "acme/app",
+			wantVersion: "sha256:4bcff63911fc",
+		},
+		{
+			name:        "image without tag nor digest, digest from purl",
+			component:   Component{Type: "container", Name: "ghcr.io/acme/app", PURL: ociPURL},
+			wantName:    "acme/app",
+			wantVersion: "sha256:4bcff63911fc",
+		},
+		{
+			name:        "image without tag nor digest, component version",
+			component:   Component{Type: "container", Name: "ghcr.io/acme/app", Version: "2024.1"},
+			wantName:    "acme/app",
+			wantVersion: "2024.1",
+		},
+		{
+			name:        "latest replaced by the configured version",
+			component:   Component{Type: "container", Name: "acme/app:latest", PURL: ociPURL},
+			latest:      "main",
+			wantName:    "acme/app",
+			wantVersion: "main",
+		},
+		{
+			name:        "latest replaced by the short digest",
+			component:   Component{Type: "container", Name: "acme/app:latest", PURL: ociPURL},
+			wantName:    "acme/app",
+			wantVersion: "sha256:4bcff63911fc",
+		},
+		{
+			name:        "latest kept without digest nor fallback",
+			component:   Component{Type: "container", Name: "acme/app:latest"},
+			wantName:    "acme/app",
+			wantVersion: "latest",
+		},
+		{
+			name:        "library",
+			component:   Component{Type: "library", Name: "lodash", Version: "4.17.21"},
+			wantName:    "lodash",
+			wantVersion: "4.17.21",
+		},
+		{
+			name:        "repository url",
+			component:   Component{Type: "application", Name: "https://github.com/acme/app.git"},
+			wantName:    "acme/app",
+			wantVersion: "",
+		},
+		{
+			name:        "filesystem scan of the working directory",
+			component:   Component{Type: "application", Name: "."},
+			wantName:    "",
+			wantVersion: "",
+		},
+		{
+			name: "missing metadata.component",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, version := FromBOM(tt.component, tt.latest)
+			if name != tt.wantName || version != tt.wantVersion {
+				t.Errorf("FromBOM() = %q, %q, want %q, %q", name, version, tt.wantName, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestReadBOMComponent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Component
+		wantErr bool
+	}{
+		{
+			name:    "component",
+			content: `{"bomFormat":"CycloneDX","metadata":{"component":{"type":"container","name":"alpine:3.19","purl":"pkg:oci/alpine"}}}`,
+			want:    Component{Type: "container", Name: "alpine:3.19", PURL: "pkg:oci/alpine"},
+		},
+		{
+			name:    "missing metadata.component",
+			content: `{"bomFormat":"CycloneDX","metadata":{"timestamp":"2024-01-01T00:00:00Z"}}`,
+		},
+		{
+			name:    "missing metadata",
+			content: `{"bomFormat":"CycloneDX"}`,
+		},
+		{
+			name:    "not json",
+			content: `<bom/>`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "sbom.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadBOMComponent(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ReadBOMComponent() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadBOMComponent() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ReadBOMComponent() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package naming
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+var funcs = template.FuncMap{
+	"slug":       Slug,
+	"trunc":      trunc,
+	"semver":     ParseSemver,
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"default": func(fallback, s string) string {
+		if s == "" {
+			return fallback
+		}
+		return s
+	},
+}
+
+// Slug lowercases s and replaces every run of characters other than letters, digits, "." and "_" with a single "-",
+// e.g. "Feature/ABC-12 Fix" becomes "feature-abc-12-fix".
+func Slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// trunc keeps the first n characters of s. The arguments are ordered for pipelines: {{.Ref | trunc 20}}.
+func trunc(n int, s string) string {
+	r := []rune(s)
+	if n < 0 || len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// Semver is a parsed semantic version.
+type Semver struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	Build      string
+}
+
+func (v Semver) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+var semverRegexp = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+
+// ParseSemver parses a semantic version, with or without "v" prefix, e.g. {{(semver .Ref).Major}}. Rendering fails
+// when the value is not a semantic version.
+func ParseSemver(s string) (Semver, error) {
+	m := semverRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return Semver{}, fmt.Errorf("%q is not a semantic version", s)
+	}
+	var v Semver
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	v.Patch, _ = strconv.Atoi(m[3])
+	v.Prerelease, v.Build = m[4], m[5]
+	return v, nil
+}
@@ -0,0 +1,128 @@
+package naming
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/ci"
+)
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "Feature/ABC-12 Fix", want: "feature-abc-12-fix"},
+		{in: "main", want: "main"},
+		{in: "release/1.2.x", want: "release-1.2.x"},
+		{in: "snake_case_branch", want: "snake_case_branch"},
+		{in: "--leading//and trailing--", want: "leading-and-trailing"},
+		{in: "Ünïcödé/Zweig", want: "ünïcödé-zweig"},
+		{in: "///", want: ""},
+		{in: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := Slug(tt.in); got != tt.want {
+			t.Errorf("Slug(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTrunc(t *testing.T) {
+	tests := []struct {
+		n    int
+		in   string
+		want string
+	}{
+		{n: 4, in: "feature", want: "feat"},
+		{n: 10, in: "feature", want: "feature"},
+		{n: 7, in: "feature", want: "feature"},
+		{n: 0, in: "feature", want: ""},
+		{n: -1, in: "feature", want: "feature"},
+		{n: 3, in: "héllo", want: "hél"},
+	}
+	for _, tt := range tests {
+		if got := trunc(tt.n, tt.in); got != tt.want {
+			t.Errorf("trunc(%d, %q) = %q, want %q", tt.n, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseSemver(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Semver
+		wantErr bool
+	}{
+		{in: "1.2.3", want: Semver{Major: 1, Minor: 2, Patch: 3}},
+		{in: "v10.0.1", want: Semver{Major: 10, Minor: 0, Patch: 1}},
+		{in: "v1.4.2-rc.1+build.7", want: Semver{Major: 1, Minor: 4, Patch: 2, Prerelease: "rc.1", Build: "build.7"}},
+		{in: "2.0.0+20240101", want: Semver{Major: 2, Build: "20240101"}},
+		{in: "1.2", wantErr: true},
+		{in: "01.2.3", wantErr: true},
+		{in: "V1.2.3", wantErr: true},
+		{in: "main", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSemver(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseSemver(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSemver(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSemver(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if s := got.String(); strings.TrimPrefix(tt.in, "v") != s {
+			t.Errorf("ParseSemver(%q).String() = %q", tt.in, s)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	data := NewData(
+		ci.Context{Provider: "gitlab", Namespace: "group", Repository: "app", Version: "Feature/ABC-12", RefType: ci.RefBranch, CommitSHA: "0123456789abcdef"},
+		Component{Name: "app"},
+		Git{Branch: "ignored", SHA: "ffffffff"},
+		[]string{"TEAM=platform"},
+	)
+
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr string
+	}{
+		{name: "fields", text: "{{.Namespace}}/{{.Project}}", want: "group/app"},
+		{name: "slug and short sha", text: "{{.Ref | slug}}-{{.ShortSHA}}", want: "feature-abc-12-01234567"},
+		{name: "trunc in pipeline", text: "{{.Ref | slug | trunc 7}}", want: "feature"},
+		{name: "env", text: "{{.Env.TEAM}}-{{.Project}}", want: "platform-app"},
+		{name: "default", text: `{{.MergeRequestID | default "none"}}`, want: "none"},
+		{name: "semver of a branch fails", text: "{{(semver .Ref).Major}}", wantErr: "is not a semantic version"},
+		{name: "empty result", text: "{{.TargetBranch}}", wantErr: "value is empty"},
+		{name: "parse error", text: "{{.Ref", wantErr: "invalid test template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render("test template", tt.text, data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Render() = %q, %v, want error containing %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Package naming renders DependencyTrack project names and versions from Go templates.
+package naming
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/ci"
+)
+
+// MaxLength is the longest project name or version DependencyTrack stores.
+const MaxLength = 255
+
+// Data is what project name and version templates are executed with.
+type Data struct {
+	// Provider is the CI provider name, empty outside CI.
+	Provider string
+	// Namespace is the group, organization or workspace owning the repository.
+	Namespace string
+	// Project is the repository name without namespace.
+	Project string
+	// Ref is the branch, tag or merge request source branch being built.
+	Ref            string
+	RefType        ci.RefType
+	MergeRequestID string
+	TargetBranch   string
+	DefaultBranch  string
+	SHA            string
+	ShortSHA       string
+	BuildURL       string
+
+	// BOM is the metadata.component of the uploaded sbom.
+	BOM Component
+	// Env holds the environment variables, e.g. {{.Env.HOME}}.
+	Env map[string]string
+	// Git is read from the working directory repository.
+	Git Git
+}
+
+// NewData merges the CI context, sbom component and git information. CI values win, git filling the reference and
+// commit when the CI provider does not expose them.
+func NewData(c ci.Context, bom Component, git Git, environ []string) Data {
+	d := Data{
+		Provider:       c.Provider,
+		Namespace:      c.Namespace,
+		Project:        c.Repository,
+		Ref:            c.Version,
+		RefType:        c.RefType,
+		MergeRequestID: c.MergeRequestID,
+		TargetBranch:   c.TargetBranch,
+		DefaultBranch:  c.DefaultBranch,
+		SHA:            c.CommitSHA,
+		BuildURL:       c.BuildURL,
+		BOM:            bom,
+		Env:            map[string]string{},
+		Git:            git,
+	}
+	if d.Project == "" {
+		d.Project = c.ProjectName
+	}
+	if d.Ref == "" {
+		switch {
+		case git.Tag != "":
+			d.Ref, d.RefType = git.Tag, ci.RefTag
+		case git.Branch != "":
+			d.Ref, d.RefType = git.Branch, ci.RefBranch
+		}
+	}
+	if d.SHA == "" {
+		d.SHA = git.SHA
+	}
+	d.ShortSHA = shortSHA(d.SHA)
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			d.Env[k] = v
+		}
+	}
+	return d
+}
+
+// Render executes a template and validates its result. name identifies the template in errors.
+func Render(name, text string, data Data) (string, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	value := strings.TrimSpace(buf.String())
+	if err := Validate(value); err != nil {
+		return "", fmt.Errorf("%s rendered %q: %w", name, value, err)
+	}
+	return value, nil
+}
+
+// Check parses a template without executing it, e.g. to validate a config file.
+func Check(text string) error {
+	_, err := template.New("").Funcs(funcs).Parse(text)
+	return err
+}
+
+// Validate checks that a rendered value can be used as a DependencyTrack project name or version.
+func Validate(value string) error {
+	switch {
+	case value == "":
+		return fmt.Errorf("value is empty")
+	case strings.Contains(value, "<no value>"):
+		return fmt.Errorf("value references a missing field")
+	case strings.IndexFunc(value, unicode.IsControl) >= 0:
+		return fmt.Errorf("value contains control characters")
+	case utf8.RuneCountInString(value) > MaxLength:
+		return fmt.Errorf("value is longer than %d characters", MaxLength)
+	}
+	return nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
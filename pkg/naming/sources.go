@@ -0,0 +1,87 @@
+package naming
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Component is the metadata.component of a CycloneDX sbom, i.e. what the sbom describes.
+type Component struct {
+	Type    string `json:"type"`
+	Group   string `json:"group"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	PURL    string `json:"purl"`
+}
+
+// ReadBOMComponent reads the metadata.component of a CycloneDX JSON sbom. It is empty when the sbom has none.
+func ReadBOMComponent(path string) (Component, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Component{}, err
+	}
+	var bom struct {
+		Metadata struct {
+			Component Component `json:"component"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(content, &bom); err != nil {
+		return Component{}, fmt.Errorf("failed to decode cyclonedx sbom %s: %w", path, err)
+	}
+	return bom.Metadata.Component, nil
+}
+
+// Git describes the checked out commit of a git repository.
+type Git struct {
+	Branch   string
+	Tag      string
+	SHA      string
+	ShortSHA string
+	Remote   string
+}
+
+// ReadGit reads the current branch, the tag pointing at HEAD, the commit and the origin URL of the repository in dir.
+// Values git cannot provide, e.g. outside a repository or without git installed, are left empty.
+func ReadGit(dir string) Git {
+	run := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	g := Git{
+		SHA:    run("rev-parse", "HEAD"),
+		Tag:    run("describe", "--tags", "--exact-match", "HEAD"),
+		Remote: stripCredentials(run("remote", "get-url", "origin")),
+	}
+	if g.SHA == "" {
+		return Git{}
+	}
+	if branch := run("rev-parse", "--abbrev-ref", "HEAD"); branch != "HEAD" {
+		g.Branch = branch
+	}
+	g.ShortSHA = shortSHA(g.SHA)
+	return g
+}
+
+// stripCredentials drops the user information of a URL, CI checkouts embedding tokens in the origin URL.
+func stripCredentials(remote string) string {
+	scheme, rest, ok := strings.Cut(remote, "://")
+	if !ok {
+		return remote
+	}
+	host, path, _ := strings.Cut(rest, "/")
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	if path == "" {
+		return scheme + "://" + host
+	}
+	return scheme + "://" + host + "/" + path
+}
@@ -0,0 +1,168 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+// Classifiers accepted by DependencyTrack for a project.
+var Classifiers = []string{
+	"APPLICATION", "FRAMEWORK", "LIBRARY", "CONTAINER", "OPERATING_SYSTEM", "DEVICE", "FIRMWARE", "FILE", "PLATFORM",
+	"DEVICE_DRIVER", "MACHINE_LEARNING_MODEL", "DATA",
+}
+
+// IsPattern reports whether s holds a "*" wildcard.
+func IsPattern(s string) bool {
+	return strings.Contains(s, "*")
+}
+
+// Match returns the projects whose name and version match the patterns, where "*" matches any characters. An empty
+// version pattern matches every version.
+func Match(ctx context.Context, client *dtrack.Client, namePattern string, versionPattern string) ([]dtrack.Project, error) {
+	if namePattern == "" {
+		return nil, fmt.Errorf("a project name pattern is required")
+	}
+	if versionPattern == "" {
+		versionPattern = "*"
+	}
+	name := globRegexp(namePattern)
+	version := globRegexp(versionPattern)
+
+	projects, err := List(ctx, client, Filter{})
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(projects, func(p dtrack.Project) bool {
+		return !name.MatchString(p.Name) || !version.MatchString(p.Version)
+	}), nil
+}
+
+func globRegexp(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
+
+// Changes are the fields set by Update. Nil fields are left alone.
+type Changes struct {
+	Description *string
+	Classifier  *string
+	Group       *string
+	// Tags replace the tags of the project.
+	Tags *[]string
+	// Properties are created, or updated when they exist.
+	Properties []dtrack.ProjectProperty
+}
+
+// Empty reports whether there is nothing to change.
+func (c Changes) Empty() bool {
+	return c.Description == nil && c.Classifier == nil && c.Group == nil && c.Tags == nil && len(c.Properties) == 0
+}
+
+// Validate checks the classifier and the properties.
+func (c Changes) Validate() error {
+	if c.Classifier != nil && !slices.Contains(Classifiers, *c.Classifier) {
+		return fmt.Errorf("invalid classifier %q, expected one of %v", *c.Classifier, Classifiers)
+	}
+	for _, p := range c.Properties {
+		if p.Group == "" || p.Name == "" {
+			return fmt.Errorf("invalid property %s/%s, group and name are required", p.Group, p.Name)
+		}
+	}
+	return nil
+}
+
+// ParseProperty reads a "group/name=value" string property.
+func ParseProperty(s string) (dtrack.ProjectProperty, error) {
+	key, value, ok := strings.Cut(s, "=")
+	group, name, okGroup := strings.Cut(key, "/")
+	if !ok || !okGroup || group == "" || name == "" {
+		return dtrack.ProjectProperty{}, fmt.Errorf("invalid property %q, expected group/name=value", s)
+	}
+	return dtrack.ProjectProperty{Group: group, Name: name, Value: value, Type: "STRING"}, nil
+}
+
+// Update applies the changes to a project and returns a description of each field that changed. Fields already
+// holding the requested value are not written.
+func Update(ctx context.Context, client *dtrack.Client, p dtrack.Project, c Changes) ([]string, error) {
+	p, err := client.Project.Get(ctx, p.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	setField := func(field string, current *string, value *string) {
+		if value == nil || *current == *value {
+			return
+		}
+		changed = append(changed, fmt.Sprintf("%s: %q -> %q", field, *current, *value))
+		*current = *value
+	}
+	setField("description", &p.Description, c.Description)
+	setField("classifier", &p.Classifier, c.Classifier)
+	setField("group", &p.Group, c.Group)
+	if c.Tags != nil {
+		current := tags(p)
+		tagList := make([]dtrack.Tag, 0, len(*c.Tags))
+		for _, t := range *c.Tags {
+			tagList = append(tagList, dtrack.Tag{Name: t})
+		}
+		p.Tags = tagList
+		if value := tags(p); !strings.EqualFold(current, value) {
+			changed = append(changed, fmt.Sprintf("tags: %q -> %q", current, value))
+		}
+	}
+	if len(changed) > 0 {
+		if _, err := client.Project.Update(ctx, p); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(c.Properties) == 0 {
+		return changed, nil
+	}
+	existing, err := dtrack.FetchAll(func(po dtrack.PageOptions) (dtrack.Page[dtrack.ProjectProperty], error) {
+		return client.ProjectProperty.GetAll(ctx, p.UUID, po)
+	})
+	if err != nil {
+		return changed, fmt.Errorf("failed to get properties: %w", err)
+	}
+	for _, prop := range c.Properties {
+		i := slices.IndexFunc(existing, func(e dtrack.ProjectProperty) bool { return e.Group == prop.Group && e.Name == prop.Name })
+		switch {
+		case i < 0:
+			if _, err := client.ProjectProperty.Create(ctx, p.UUID, prop); err != nil {
+				return changed, fmt.Errorf("failed to create property %s/%s: %w", prop.Group, prop.Name, err)
+			}
+			changed = append(changed, fmt.Sprintf("property %s/%s: created %q", prop.Group, prop.Name, prop.Value))
+		case existing[i].Value != prop.Value:
+			prop.Type = existing[i].Type
+			if _, err := client.ProjectProperty.Update(ctx, p.UUID, prop); err != nil {
+				return changed, fmt.Errorf("failed to update property %s/%s: %w", prop.Group, prop.Name, err)
+			}
+			changed = append(changed, fmt.Sprintf("property %s/%s: %q -> %q", prop.Group, prop.Name, existing[i].Value, prop.Value))
+		}
+	}
+	return changed, nil
+}
+
+// SetActive activates or deactivates a project. It reports false when the project was already in that state.
+func SetActive(ctx context.Context, client *dtrack.Client, p dtrack.Project, active bool) (bool, error) {
+	p, err := client.Project.Get(ctx, p.UUID)
+	if err != nil {
+		return false, err
+	}
+	if p.Active == active {
+		return false, nil
+	}
+	p.Active = active
+	_, err = client.Project.Update(ctx, p)
+	return err == nil, err
+}
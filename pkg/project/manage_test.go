@@ -0,0 +1,116 @@
+package project
+
+import (
+	"context"
+	"testing"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		want    bool
+	}{
+		{pattern: "web", value: "web", want: true},
+		{pattern: "web", value: "web-api", want: false},
+		{pattern: "web", value: "Web", want: false},
+		{pattern: "feature-*", value: "feature-login", want: true},
+		{pattern: "feature-*", value: "feature-", want: true},
+		{pattern: "feature-*", value: "main", want: false},
+		{pattern: "*-rc*", value: "1.0-rc2", want: true},
+		{pattern: "*", value: "", want: true},
+		{pattern: "1.0.*", value: "1.0.3", want: true},
+		{pattern: "1.0.*", value: "1.013", want: false},
+		{pattern: "team/(api)+?", value: "team/(api)+?", want: true},
+		{pattern: "team/(api)+?", value: "team/apiapi", want: false},
+		{pattern: "[a-z]*", value: "web", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.value, func(t *testing.T) {
+			if got := globRegexp(tt.pattern).MatchString(tt.value); got != tt.want {
+				t.Errorf("globRegexp(%q) matches %q = %v, want %v", tt.pattern, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPattern(t *testing.T) {
+	for s, want := range map[string]bool{"feature-*": true, "*": true, "main": false, "": false, "v1.?": false} {
+		if got := IsPattern(s); got != want {
+			t.Errorf("IsPattern(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		project string
+		version string
+		want    []string
+	}{
+		{name: "every version of a name", project: "web", want: []string{"web 1.0", "web 2.0"}},
+		{name: "version pattern", project: "web", version: "2.*", want: []string{"web 2.0"}},
+		{name: "name pattern", project: "*r", version: "main", want: []string{"Worker main"}},
+		{name: "every project", project: "*", version: "main", want: []string{"Worker main", "api main"}},
+		{name: "name is case sensitive", project: "worker"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := fakeProjects(t)
+			got, err := Match(context.Background(), client, tt.project, tt.version)
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Match() = %v, want %v", got, tt.want)
+			}
+			for i, p := range got {
+				if p.Name+" "+p.Version != tt.want[i] {
+					t.Errorf("Match()[%d] = %s %s, want %s", i, p.Name, p.Version, tt.want[i])
+				}
+			}
+		})
+	}
+
+	if _, err := Match(context.Background(), nil, "", "*"); err == nil {
+		t.Error("Match() without name pattern error = nil")
+	}
+}
+
+func TestParseProperty(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    dtrack.ProjectProperty
+		wantErr bool
+	}{
+		{in: "ci/owner=team-a", want: dtrack.ProjectProperty{Group: "ci", Name: "owner", Value: "team-a", Type: "STRING"}},
+		{in: "ci/url=https://ci.example.com/?a=b", want: dtrack.ProjectProperty{Group: "ci", Name: "url", Value: "https://ci.example.com/?a=b", Type: "STRING"}},
+		{in: "ci/owner=", want: dtrack.ProjectProperty{Group: "ci", Name: "owner", Type: "STRING"}},
+		{in: "ci/team/owner=a", want: dtrack.ProjectProperty{Group: "ci", Name: "team/owner", Value: "a", Type: "STRING"}},
+		{in: "ci/owner", wantErr: true},
+		{in: "owner=team-a", wantErr: true},
+		{in: "/owner=team-a", wantErr: true},
+		{in: "ci/=team-a", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseProperty(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseProperty() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseProperty() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseProperty() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
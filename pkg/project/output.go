@@ -0,0 +1,142 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	dtrack "github.com/DependencyTrack/client-go"
+	"go.yaml.in/yaml/v3"
+)
+
+// Output formats supported by Write and WriteProject.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+)
+
+// Write renders a list of projects to w in the given format.
+func Write(w io.Writer, format string, projects []dtrack.Project) error {
+	if projects == nil {
+		projects = []dtrack.Project{}
+	}
+	switch format {
+	case FormatTable, "":
+		return Table(w, projects)
+	case FormatJSON:
+		return writeJSON(w, projects)
+	case FormatYAML:
+		return writeYAML(w, projects)
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// WriteProject renders one project to w in the given format.
+func WriteProject(w io.Writer, format string, p dtrack.Project) error {
+	switch format {
+	case FormatTable, "":
+		return Details(w, p)
+	case FormatJSON:
+		return writeJSON(w, p)
+	case FormatYAML:
+		return writeYAML(w, p)
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// Table renders projects as an aligned text table.
+func Table(w io.Writer, projects []dtrack.Project) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "UUID\tNAME\tVERSION\tACTIVE\tTAGS\tPARENT\tLAST BOM IMPORT")
+	for _, p := range projects {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\t%s\n", p.UUID, p.Name, orDash(p.Version), p.Active, orDash(tags(p)),
+			orDash(parent(p)), orDash(lastBOMImport(p)))
+	}
+	return tw.Flush()
+}
+
+// Details renders one project as aligned key/value lines.
+func Details(w io.Writer, p dtrack.Project) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, row := range [][2]string{
+		{"UUID", p.UUID.String()},
+		{"Name", p.Name},
+		{"Version", p.Version},
+		{"Group", p.Group},
+		{"Classifier", p.Classifier},
+		{"Description", p.Description},
+		{"PURL", p.PURL},
+		{"Active", fmt.Sprint(p.Active)},
+		{"Tags", tags(p)},
+		{"Parent", parent(p)},
+		{"Last BOM import", lastBOMImport(p)},
+		{"Vulnerabilities", fmt.Sprintf("%d critical, %d high, %d medium, %d low, %d unassigned", p.Metrics.Critical,
+			p.Metrics.High, p.Metrics.Medium, p.Metrics.Low, p.Metrics.Unassigned)},
+		{"Policy violations", fmt.Sprint(p.Metrics.PolicyViolationsTotal)},
+	} {
+		fmt.Fprintf(tw, "%s:\t%s\n", row[0], orDash(row[1]))
+	}
+	for _, prop := range p.Properties {
+		fmt.Fprintf(tw, "Property %s/%s:\t%s\n", prop.Group, prop.Name, prop.Value)
+	}
+	return tw.Flush()
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeYAML goes through JSON so that the YAML keys are the DependencyTrack API field names.
+func writeYAML(w io.Writer, v any) error {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var doc any
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func tags(p dtrack.Project) string {
+	names := make([]string, 0, len(p.Tags))
+	for _, t := range p.Tags {
+		names = append(names, t.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func parent(p dtrack.Project) string {
+	if p.ParentRef == nil {
+		return ""
+	}
+	return p.ParentRef.UUID.String()
+}
+
+func lastBOMImport(p dtrack.Project) string {
+	if p.LastBOMImport == 0 {
+		return ""
+	}
+	return time.UnixMilli(int64(p.LastBOMImport)).UTC().Format(time.DateTime)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
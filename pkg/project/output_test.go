@@ -0,0 +1,121 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	dtrack "github.com/DependencyTrack/client-go"
+	"github.com/google/uuid"
+)
+
+func TestWrite(t *testing.T) {
+	imported := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	ps := []dtrack.Project{
+		{
+			UUID:          uuid.MustParse("3e671687-395b-41f5-a30f-a58921a69b79"),
+			Name:          "web",
+			Version:       "1.0",
+			Active:        true,
+			Tags:          []dtrack.Tag{{Name: "team-a"}, {Name: "prod"}},
+			ParentRef:     &dtrack.ParentRef{UUID: parentUUID},
+			LastBOMImport: int(imported.UnixMilli()),
+		},
+		{UUID: uuid.MustParse("9c1a2b3c-4d5e-4f60-8a7b-8c9d0e1f2a3b"), Name: "api"},
+	}
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatTable, ps); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		want := "" +
+			"UUID                                  NAME  VERSION  ACTIVE  TAGS         PARENT                                LAST BOM IMPORT\n" +
+			"3e671687-395b-41f5-a30f-a58921a69b79  web   1.0      true    team-a,prod  5e0f5fd4-7ba0-4ac5-8fb8-a8e1ac7a6f7e  2024-03-01 12:30:00\n" +
+			"9c1a2b3c-4d5e-4f60-8a7b-8c9d0e1f2a3b  api   -        false   -            -                                     -\n"
+		if buf.String() != want {
+			t.Errorf("Write() =\n%s\nwant\n%s", buf.String(), want)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatJSON, ps); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		var got []dtrack.Project
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("Write() wrote invalid json: %v\n%s", err, buf.String())
+		}
+		if len(got) != 2 || got[0].Name != "web" || got[0].ParentRef.UUID != parentUUID || len(got[0].Tags) != 2 {
+			t.Errorf("Write() = %s", buf.String())
+		}
+		if !strings.Contains(buf.String(), "\n  {\n    \"uuid\": ") {
+			t.Errorf("Write() is not indented:\n%s", buf.String())
+		}
+	})
+
+	t.Run("json without projects", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatJSON, nil); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if buf.String() != "[]\n" {
+			t.Errorf("Write() = %q, want an empty list", buf.String())
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatYAML, ps[1:]); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if !strings.Contains(buf.String(), "- name: api\n") && !strings.Contains(buf.String(), "  name: api\n") {
+			t.Errorf("Write() =\n%s", buf.String())
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		if err := Write(&bytes.Buffer{}, "xml", ps); err == nil || err.Error() != "unsupported output format: xml" {
+			t.Errorf("Write() error = %v", err)
+		}
+	})
+}
+
+func TestWriteProject(t *testing.T) {
+	p := dtrack.Project{
+		UUID:       uuid.MustParse("3e671687-395b-41f5-a30f-a58921a69b79"),
+		Name:       "web",
+		Version:    "1.0",
+		Classifier: "APPLICATION",
+		Metrics:    dtrack.ProjectMetrics{Critical: 1, High: 2, PolicyViolationsTotal: 3},
+		Properties: []dtrack.ProjectProperty{{Group: "ci", Name: "repo", Value: "team/web"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteProject(&buf, FormatTable, p); err != nil {
+		t.Fatalf("WriteProject() error = %v", err)
+	}
+	for _, want := range []string{
+		"Name:               web\n",
+		"Group:              -\n",
+		"Vulnerabilities:    1 critical, 2 high, 0 medium, 0 low, 0 unassigned\n",
+		"Policy violations:  3\n",
+		"Property ci/repo:   team/web\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteProject() =\n%s\nwant it to contain %q", buf.String(), want)
+		}
+	}
+
+	buf.Reset()
+	if err := WriteProject(&buf, FormatJSON, p); err != nil {
+		t.Fatalf("WriteProject() error = %v", err)
+	}
+	var got dtrack.Project
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil || got.UUID != p.UUID || got.Metrics.High != 2 {
+		t.Errorf("WriteProject() = %s, error %v", buf.String(), err)
+	}
+}
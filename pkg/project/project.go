@@ -0,0 +1,105 @@
+// Package project lists and manages DependencyTrack projects.
+package project
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	dtrack "github.com/DependencyTrack/client-go"
+	"github.com/google/uuid"
+)
+
+// Status values of Filter.Status.
+const (
+	StatusAll      = "all"
+	StatusActive   = "active"
+	StatusInactive = "inactive"
+)
+
+// Filter selects projects. Empty fields are not checked.
+type Filter struct {
+	// Name matches projects whose name contains it, case-insensitively.
+	Name   string
+	Tag    string
+	Status string
+	// Parent restricts the list to the direct children of a project.
+	Parent uuid.UUID
+}
+
+// Validate checks the status of the filter.
+func (f Filter) Validate() error {
+	switch f.Status {
+	case "", StatusAll, StatusActive, StatusInactive:
+		return nil
+	default:
+		return fmt.Errorf("invalid status %q, expected one of [%s, %s, %s]", f.Status, StatusAll, StatusActive, StatusInactive)
+	}
+}
+
+// List returns the projects matching the filter, sorted by name and version. The parent or tag narrows the request
+// sent to DependencyTrack, the other conditions are checked locally.
+func List(ctx context.Context, client *dtrack.Client, f Filter) ([]dtrack.Project, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	var fetch func(po dtrack.PageOptions) (dtrack.Page[dtrack.Project], error)
+	switch {
+	case f.Parent != uuid.Nil:
+		fetch = func(po dtrack.PageOptions) (dtrack.Page[dtrack.Project], error) {
+			return client.Project.GetChildren(ctx, f.Parent, po)
+		}
+	case f.Tag != "":
+		fetch = func(po dtrack.PageOptions) (dtrack.Page[dtrack.Project], error) {
+			return client.Project.GetAllByTag(ctx, f.Tag, f.Status == StatusActive, false, po)
+		}
+	default:
+		fetch = func(po dtrack.PageOptions) (dtrack.Page[dtrack.Project], error) {
+			return client.Project.GetAll(ctx, po)
+		}
+	}
+	projects, err := dtrack.FetchAll(fetch)
+	if err != nil {
+		return nil, err
+	}
+
+	projects = slices.DeleteFunc(projects, func(p dtrack.Project) bool { return !f.Matches(p) })
+	slices.SortFunc(projects, func(a, b dtrack.Project) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Version, b.Version)
+	})
+	return projects, nil
+}
+
+// Matches reports whether the project passes the filter.
+func (f Filter) Matches(p dtrack.Project) bool {
+	if f.Name != "" && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(f.Name)) {
+		return false
+	}
+	if f.Tag != "" && !HasTag(p, f.Tag) {
+		return false
+	}
+	switch f.Status {
+	case StatusActive:
+		if !p.Active {
+			return false
+		}
+	case StatusInactive:
+		if p.Active {
+			return false
+		}
+	}
+	if f.Parent != uuid.Nil && (p.ParentRef == nil || p.ParentRef.UUID != f.Parent) {
+		return false
+	}
+	return true
+}
+
+// HasTag reports whether the project carries the tag, case-insensitively as DependencyTrack stores tags lowercased.
+func HasTag(p dtrack.Project, tag string) bool {
+	return slices.ContainsFunc(p.Tags, func(t dtrack.Tag) bool { return strings.EqualFold(t.Name, tag) })
+}
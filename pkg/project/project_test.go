@@ -0,0 +1,170 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	dtrack "github.com/DependencyTrack/client-go"
+	"github.com/google/uuid"
+)
+
+var (
+	parentUUID = uuid.MustParse("5e0f5fd4-7ba0-4ac5-8fb8-a8e1ac7a6f7e")
+	projects   = []dtrack.Project{
+		{UUID: uuid.New(), Name: "web", Version: "2.0", Active: true, Tags: []dtrack.Tag{{Name: "team-a"}}},
+		{UUID: uuid.New(), Name: "web", Version: "1.0", Active: false, Tags: []dtrack.Tag{{Name: "team-a"}}},
+		{UUID: uuid.New(), Name: "api", Version: "main", Active: true, ParentRef: &dtrack.ParentRef{UUID: parentUUID}},
+		{UUID: uuid.New(), Name: "Worker", Version: "main", Active: true, Tags: []dtrack.Tag{{Name: "team-b"}}},
+	}
+)
+
+// fakeProjects serves the projects on the endpoints used by List and records the path and query of each request.
+func fakeProjects(t *testing.T) (*dtrack.Client, *[]string) {
+	var requests []string
+	serve := func(w http.ResponseWriter, r *http.Request, ps []dtrack.Project) {
+		requests = append(requests, r.URL.Path+"?"+r.URL.Query().Get("excludeInactive"))
+		w.Header().Set("X-Total-Count", strconv.Itoa(len(ps)))
+		_ = json.NewEncoder(w).Encode(ps)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/version", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(dtrack.About{Version: "4.11.0"})
+	})
+	mux.HandleFunc("GET /api/v1/project", func(w http.ResponseWriter, r *http.Request) {
+		serve(w, r, projects)
+	})
+	mux.HandleFunc("GET /api/v1/project/tag/{tag}", func(w http.ResponseWriter, r *http.Request) {
+		var tagged []dtrack.Project
+		for _, p := range projects {
+			if HasTag(p, r.PathValue("tag")) {
+				tagged = append(tagged, p)
+			}
+		}
+		serve(w, r, tagged)
+	})
+	mux.HandleFunc("GET /api/v1/project/"+parentUUID.String()+"/children", func(w http.ResponseWriter, r *http.Request) {
+		serve(w, r, projects[2:3])
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := dtrack.NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, &requests
+}
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		name        string
+		filter      Filter
+		wantRequest string
+		want        []string
+	}{
+		{
+			name:        "every project sorted by name and version",
+			wantRequest: "/api/v1/project?",
+			want:        []string{"Worker main", "api main", "web 1.0", "web 2.0"},
+		},
+		{
+			name:        "name is a case-insensitive substring",
+			filter:      Filter{Name: "WOR"},
+			wantRequest: "/api/v1/project?",
+			want:        []string{"Worker main"},
+		},
+		{
+			name:        "tag is requested from DependencyTrack",
+			filter:      Filter{Tag: "Team-A"},
+			wantRequest: "/api/v1/project/tag/Team-A?false",
+			want:        []string{"web 1.0", "web 2.0"},
+		},
+		{
+			name:        "tag of active projects",
+			filter:      Filter{Tag: "team-a", Status: StatusActive},
+			wantRequest: "/api/v1/project/tag/team-a?true",
+			want:        []string{"web 2.0"},
+		},
+		{
+			name:        "inactive projects",
+			filter:      Filter{Status: StatusInactive},
+			wantRequest: "/api/v1/project?",
+			want:        []string{"web 1.0"},
+		},
+		{
+			name:        "children of a parent",
+			filter:      Filter{Parent: parentUUID, Status: StatusAll},
+			wantRequest: "/api/v1/project/" + parentUUID.String() + "/children?",
+			want:        []string{"api main"},
+		},
+		{
+			name:        "children of a parent with a tag",
+			filter:      Filter{Parent: parentUUID, Tag: "team-a"},
+			wantRequest: "/api/v1/project/" + parentUUID.String() + "/children?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, requests := fakeProjects(t)
+			got, err := List(context.Background(), client, tt.filter)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(*requests) != 1 || (*requests)[0] != tt.wantRequest {
+				t.Errorf("List() requests = %v, want %s", *requests, tt.wantRequest)
+			}
+			var names []string
+			for _, p := range got {
+				names = append(names, p.Name+" "+p.Version)
+			}
+			if len(names) != len(tt.want) {
+				t.Fatalf("List() = %v, want %v", names, tt.want)
+			}
+			for i := range names {
+				if names[i] != tt.want[i] {
+					t.Errorf("List() = %v, want %v", names, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestListInvalidStatus(t *testing.T) {
+	if _, err := List(context.Background(), nil, Filter{Status: "archived"}); err == nil {
+		t.Error("List() error = nil, want an invalid status error")
+	}
+}
+
+func TestFilterMatches(t *testing.T) {
+	child := dtrack.Project{Name: "api", Active: true, ParentRef: &dtrack.ParentRef{UUID: parentUUID}}
+	inactive := dtrack.Project{Name: "web", Active: false, Tags: []dtrack.Tag{{Name: "team-a"}, {Name: "legacy"}}}
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		project dtrack.Project
+		want    bool
+	}{
+		{name: "empty filter", project: inactive, want: true},
+		{name: "tag among several", filter: Filter{Tag: "LEGACY"}, project: inactive, want: true},
+		{name: "missing tag", filter: Filter{Tag: "team-b"}, project: inactive, want: false},
+		{name: "active only", filter: Filter{Status: StatusActive}, project: inactive, want: false},
+		{name: "inactive only", filter: Filter{Status: StatusInactive}, project: inactive, want: true},
+		{name: "all statuses", filter: Filter{Status: StatusAll}, project: inactive, want: true},
+		{name: "parent", filter: Filter{Parent: parentUUID}, project: child, want: true},
+		{name: "other parent", filter: Filter{Parent: uuid.New()}, project: child, want: false},
+		{name: "no parent", filter: Filter{Parent: parentUUID}, project: inactive, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(tt.project); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Package report renders DependencyTrack findings for humans.
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	dtrack "github.com/DependencyTrack/client-go"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/findings"
+)
+
+// maxRows caps each Markdown table so that notes stay readable and below the size limits of the CI platforms.
+const maxRows = 50
+
+// Summary is the content of a findings report for one project version.
+type Summary struct {
+	ProjectName    string
+	ProjectVersion string
+	// Baseline is the version the findings were compared with. Empty when no baseline was found, in which case
+	// every finding is reported as introduced.
+	Baseline   string
+	Findings   findings.Diff
+	Violations findings.ViolationDiff
+}
+
+// Markdown renders the summary as GitLab/GitHub flavored Markdown.
+func Markdown(s Summary) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "### Dependency-Track: %s @ %s\n\n", escape(s.ProjectName), escape(s.ProjectVersion))
+	if s.Baseline != "" {
+		fmt.Fprintf(&b, "Compared with version `%s`.\n\n", s.Baseline)
+	} else {
+		b.WriteString("No baseline version found, every finding is reported as new.\n\n")
+	}
+
+	fmt.Fprintf(&b, "| | New | Fixed | Unchanged |\n|---|---:|---:|---:|\n")
+	fmt.Fprintf(&b, "| Vulnerabilities | %d | %d | %d |\n", len(s.Findings.Introduced), len(s.Findings.Fixed), len(s.Findings.Unchanged))
+	fmt.Fprintf(&b, "| Policy violations | %d | %d | %d |\n\n", len(s.Violations.Introduced), len(s.Violations.Fixed), len(s.Violations.Unchanged))
+
+	writeFindings(&b, "New vulnerabilities", s.Findings.Introduced)
+	writeFindings(&b, "Fixed vulnerabilities", s.Findings.Fixed)
+	writeViolations(&b, "New policy violations", s.Violations.Introduced)
+	writeViolations(&b, "Fixed policy violations", s.Violations.Fixed)
+
+	return b.String()
+}
+
+func writeFindings(b *strings.Builder, title string, f []dtrack.Finding) {
+	if len(f) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "#### %s\n\n| Severity | Vulnerability | Component | Version |\n|---|---|---|---|\n", title)
+	for i, finding := range f {
+		if i == maxRows {
+			fmt.Fprintf(b, "\n_… and %d more._\n", len(f)-maxRows)
+			break
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | %s |\n",
+			finding.Vulnerability.Severity,
+			escape(finding.Vulnerability.VulnID),
+			escape(componentName(finding.Component.Group, finding.Component.Name)),
+			escape(finding.Component.Version),
+		)
+	}
+	b.WriteString("\n")
+}
+
+func writeViolations(b *strings.Builder, title string, v []dtrack.PolicyViolation) {
+	if len(v) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "#### %s\n\n| Type | Policy | Component | Version |\n|---|---|---|---|\n", title)
+	for i, violation := range v {
+		if i == maxRows {
+			fmt.Fprintf(b, "\n_… and %d more._\n", len(v)-maxRows)
+			break
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | %s |\n",
+			violation.Type,
+			escape(policyName(violation)),
+			escape(componentName(violation.Component.Group, violation.Component.Name)),
+			escape(violation.Component.Version),
+		)
+	}
+	b.WriteString("\n")
+}
+
+func componentName(group string, name string) string {
+	if group == "" {
+		return name
+	}
+	return group + "/" + name
+}
+
+func policyName(v dtrack.PolicyViolation) string {
+	if v.PolicyCondition == nil || v.PolicyCondition.Policy == nil {
+		return v.Text
+	}
+	return v.PolicyCondition.Policy.Name
+}
+
+// escape keeps user controlled values from breaking the Markdown tables.
+func escape(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
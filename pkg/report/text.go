@@ -0,0 +1,152 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+// Output formats supported by Write.
+const (
+	FormatTable    = "table"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+// Write renders the summary to w in the given format.
+func Write(w io.Writer, format string, s Summary) error {
+	switch format {
+	case FormatTable, "":
+		return Table(w, s)
+	case FormatJSON:
+		return JSON(w, s)
+	case FormatMarkdown:
+		_, err := io.WriteString(w, Markdown(s))
+		return err
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// Table renders the summary as an aligned text table.
+func Table(w io.Writer, s Summary) error {
+	baseline := s.Baseline
+	if baseline == "" {
+		baseline = "none"
+	}
+	fmt.Fprintf(w, "Project %s @ %s, baseline %s\n", s.ProjectName, s.ProjectVersion, baseline)
+	fmt.Fprintf(w, "Vulnerabilities: %d introduced, %d fixed, %d unchanged\n", len(s.Findings.Introduced), len(s.Findings.Fixed), len(s.Findings.Unchanged))
+	fmt.Fprintf(w, "Policy violations: %d introduced, %d fixed, %d unchanged\n\n", len(s.Violations.Introduced), len(s.Violations.Fixed), len(s.Violations.Unchanged))
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tSEVERITY\tVULNERABILITY\tCOMPONENT\tVERSION")
+	for _, group := range []struct {
+		status   string
+		findings []dtrack.Finding
+	}{
+		{"introduced", s.Findings.Introduced},
+		{"fixed", s.Findings.Fixed},
+		{"unchanged", s.Findings.Unchanged},
+	} {
+		for _, f := range group.findings {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", group.status, f.Vulnerability.Severity, f.Vulnerability.VulnID,
+				componentName(f.Component.Group, f.Component.Name), f.Component.Version)
+		}
+	}
+	return tw.Flush()
+}
+
+type jsonFinding struct {
+	VulnID            string `json:"vulnId"`
+	Source            string `json:"source"`
+	Severity          string `json:"severity"`
+	Component         string `json:"component"`
+	Version           string `json:"version"`
+	PURL              string `json:"purl,omitempty"`
+	ComponentUUID     string `json:"componentUuid"`
+	VulnerabilityUUID string `json:"vulnerabilityUuid"`
+}
+
+type jsonViolation struct {
+	Type      string `json:"type"`
+	Policy    string `json:"policy"`
+	Component string `json:"component"`
+	Version   string `json:"version"`
+	PURL      string `json:"purl,omitempty"`
+}
+
+type jsonFindingDiff struct {
+	Introduced []jsonFinding `json:"introduced"`
+	Fixed      []jsonFinding `json:"fixed"`
+	Unchanged  []jsonFinding `json:"unchanged"`
+}
+
+type jsonViolationDiff struct {
+	Introduced []jsonViolation `json:"introduced"`
+	Fixed      []jsonViolation `json:"fixed"`
+	Unchanged  []jsonViolation `json:"unchanged"`
+}
+
+type jsonSummary struct {
+	Project          string            `json:"project"`
+	Version          string            `json:"version"`
+	Baseline         string            `json:"baseline,omitempty"`
+	Vulnerabilities  jsonFindingDiff   `json:"vulnerabilities"`
+	PolicyViolations jsonViolationDiff `json:"policyViolations"`
+}
+
+// JSON renders the summary as an indented JSON document.
+func JSON(w io.Writer, s Summary) error {
+	out := jsonSummary{
+		Project:  s.ProjectName,
+		Version:  s.ProjectVersion,
+		Baseline: s.Baseline,
+		Vulnerabilities: jsonFindingDiff{
+			Introduced: toJSONFindings(s.Findings.Introduced),
+			Fixed:      toJSONFindings(s.Findings.Fixed),
+			Unchanged:  toJSONFindings(s.Findings.Unchanged),
+		},
+		PolicyViolations: jsonViolationDiff{
+			Introduced: toJSONViolations(s.Violations.Introduced),
+			Fixed:      toJSONViolations(s.Violations.Fixed),
+			Unchanged:  toJSONViolations(s.Violations.Unchanged),
+		},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func toJSONFindings(f []dtrack.Finding) []jsonFinding {
+	out := make([]jsonFinding, 0, len(f))
+	for _, finding := range f {
+		out = append(out, jsonFinding{
+			VulnID:            finding.Vulnerability.VulnID,
+			Source:            finding.Vulnerability.Source,
+			Severity:          finding.Vulnerability.Severity,
+			Component:         componentName(finding.Component.Group, finding.Component.Name),
+			Version:           finding.Component.Version,
+			PURL:              finding.Component.PURL,
+			ComponentUUID:     finding.Component.UUID.String(),
+			VulnerabilityUUID: finding.Vulnerability.UUID.String(),
+		})
+	}
+	return out
+}
+
+func toJSONViolations(v []dtrack.PolicyViolation) []jsonViolation {
+	out := make([]jsonViolation, 0, len(v))
+	for _, violation := range v {
+		out = append(out, jsonViolation{
+			Type:      violation.Type,
+			Policy:    policyName(violation),
+			Component: componentName(violation.Component.Group, violation.Component.Name),
+			Version:   violation.Component.Version,
+			PURL:      violation.Component.PURL,
+		})
+	}
+	return out
+}
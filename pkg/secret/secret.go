@@ -0,0 +1,74 @@
+// Package secret reads secrets from files, helper commands and *_FILE environment variables, so that they do not
+// appear on the command line.
+package secret
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+)
+
+// CommandTimeout bounds the run of a helper command.
+const CommandTimeout = 30 * time.Second
+
+// ReadFile returns the content of a secret file, such as a Docker or Kubernetes secret, without trailing newline.
+func ReadFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	value := strings.TrimRight(string(content), "\r\n")
+	if value == "" {
+		return "", fmt.Errorf("secret file %s is empty", path)
+	}
+	return value, nil
+}
+
+// Command runs a helper command through the shell and returns its standard output without trailing newline, e.g.
+// "vault kv get -field=apikey secret/dependencytrack" or "pass show dependencytrack/apikey".
+func Command(ctx context.Context, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, CommandTimeout)
+	defer cancel()
+
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, shell, flag, command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("secret command %q failed: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+	value := strings.TrimRight(stdout.String(), "\r\n")
+	if value == "" {
+		return "", fmt.Errorf("secret command %q printed nothing", command)
+	}
+	return value, nil
+}
+
+// EnvFiles holds the secrets given through *_FILE environment variables, for the keys without file flag.
+type EnvFiles struct {
+	GitlabToken string `env:"GITLAB_TOKEN_FILE,file"`
+	Password    string `env:"PASSWORD_FILE,file"`
+}
+
+// ReadEnvFiles reads the files named by the *_FILE environment variables of the prefix. Secrets whose variable is not
+// set are empty.
+func ReadEnvFiles(prefix string) (EnvFiles, error) {
+	files, err := env.ParseAsWithOptions[EnvFiles](env.Options{Prefix: prefix + "_"})
+	if err != nil {
+		return EnvFiles{}, fmt.Errorf("failed to read secret files: %w", err)
+	}
+	files.GitlabToken = strings.TrimRight(files.GitlabToken, "\r\n")
+	files.Password = strings.TrimRight(files.Password, "\r\n")
+	return files, nil
+}
@@ -0,0 +1,155 @@
+package triage
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.yaml.in/yaml/v3"
+)
+
+// Entry is the textual form of a decision, as given on the command line or in a bulk file.
+type Entry struct {
+	Component     string `yaml:"component"`
+	Vulnerability string `yaml:"vulnerability"`
+	State         string `yaml:"state"`
+	Justification string `yaml:"justification"`
+	Response      string `yaml:"response"`
+	Details       string `yaml:"details"`
+	Comment       string `yaml:"comment"`
+	Suppressed    bool   `yaml:"suppressed"`
+}
+
+// Decision validates the entry and converts it. The component is either a component UUID or a purl.
+func (e Entry) Decision() (Decision, error) {
+	var errs []error
+	if e.Vulnerability == "" {
+		errs = append(errs, fmt.Errorf("vulnerability is required"))
+	}
+	if e.Component == "" {
+		errs = append(errs, fmt.Errorf("component is required"))
+	}
+	// Without a state, Apply would only look at the suppression and drop the details and comment.
+	if e.State == "" {
+		errs = append(errs, fmt.Errorf("state is required"))
+	}
+	state, err := ParseState(e.State)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	justification, err := ParseJustification(e.Justification)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	response, err := ParseResponse(e.Response)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return Decision{}, errors.Join(errs...)
+	}
+
+	d := Decision{
+		VulnID:        e.Vulnerability,
+		State:         state,
+		Justification: justification,
+		Response:      response,
+		Details:       e.Details,
+		Comment:       e.Comment,
+		Suppressed:    e.Suppressed,
+		Force:         true,
+	}
+	if id, err := uuid.Parse(e.Component); err == nil {
+		d.ComponentUUID = id
+	} else {
+		d.PURLs = []string{e.Component}
+	}
+	return d, nil
+}
+
+// ParseBulkFile reads entries from a CSV file with a header row naming the Entry fields, or from a YAML list.
+// Every invalid entry is reported at once.
+func ParseBulkFile(path string) ([]Decision, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		entries, err = parseCSV(content)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &entries)
+	default:
+		return nil, fmt.Errorf("unsupported bulk file %s, expected .csv, .yaml or .yml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	var (
+		decisions []Decision
+		errs      []error
+	)
+	for i, e := range entries {
+		d, err := e.Decision()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("entry %d (%s): %w", i+1, e.Vulnerability, err))
+			continue
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions, errors.Join(errs...)
+}
+
+func parseCSV(content []byte) ([]Entry, error) {
+	r := csv.NewReader(bytes.NewReader(content))
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := map[string]int{}
+	for i, name := range records[0] {
+		header[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	field := func(record []string, name string) string {
+		i, ok := header[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	entries := make([]Entry, 0, len(records)-1)
+	for line, record := range records[1:] {
+		e := Entry{
+			Component:     field(record, "component"),
+			Vulnerability: field(record, "vulnerability"),
+			State:         field(record, "state"),
+			Justification: field(record, "justification"),
+			Response:      field(record, "response"),
+			Details:       field(record, "details"),
+			Comment:       field(record, "comment"),
+		}
+		if s := field(record, "suppressed"); s != "" {
+			e.Suppressed, err = strconv.ParseBool(s)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid suppressed value %q", line+2, s)
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
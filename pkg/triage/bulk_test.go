@@ -0,0 +1,156 @@
+package triage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	dtrack "github.com/DependencyTrack/client-go"
+	"github.com/google/uuid"
+)
+
+func TestParseBulkFile(t *testing.T) {
+	componentUUID := uuid.MustParse("3b5e2a4c-5a0e-4b7e-9a55-2a1f0c9d8e01")
+	notAffected := Decision{
+		VulnID:        "CVE-2024-0001",
+		PURLs:         []string{"pkg:npm/lodash@4.17.20"},
+		State:         dtrack.AnalysisStateNotAffected,
+		Justification: dtrack.AnalysisJustificationCodeNotReachable,
+		Details:       "only used in tests",
+		Suppressed:    true,
+		Force:         true,
+	}
+	exploitable := Decision{
+		VulnID:        "GHSA-xxxx-yyyy-zzzz",
+		ComponentUUID: componentUUID,
+		State:         dtrack.AnalysisStateExploitable,
+		Response:      dtrack.AnalysisResponseUpdate,
+		Comment:       "fix planned, see ticket",
+		Force:         true,
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []Decision
+		wantErr []string
+	}{
+		{
+			name: "csv with header mapping and blank lines",
+			file: "triage.csv",
+			content: `Vulnerability, Component, State, Justification, Details, Suppressed
+
+CVE-2024-0001, pkg:npm/lodash@4.17.20, not_affected, code-not-reachable, only used in tests, true
+
+`,
+			want: []Decision{notAffected},
+		},
+		{
+			name: "csv columns in any order, missing ones left empty",
+			file: "triage.CSV",
+			content: `comment,response,state,component,vulnerability
+"fix planned, see ticket",update,EXPLOITABLE,` + componentUUID.String() + `,GHSA-xxxx-yyyy-zzzz
+`,
+			want: []Decision{exploitable},
+		},
+		{
+			name: "yaml list",
+			file: "triage.yaml",
+			content: `
+- vulnerability: CVE-2024-0001
+  component: pkg:npm/lodash@4.17.20
+  state: NOT_AFFECTED
+  justification: CODE_NOT_REACHABLE
+  details: only used in tests
+  suppressed: true
+
+- vulnerability: GHSA-xxxx-yyyy-zzzz
+  component: ` + componentUUID.String() + `
+  state: exploitable
+  response: update
+  comment: fix planned, see ticket
+`,
+			want: []Decision{notAffected, exploitable},
+		},
+		{
+			name:    "header only",
+			file:    "triage.csv",
+			content: "vulnerability,component,state\n",
+		},
+		{
+			name: "every invalid entry reported",
+			file: "triage.yml",
+			content: `
+- vulnerability: CVE-2024-0001
+  component: pkg:npm/lodash@4.17.20
+  state: ignored
+- component: pkg:npm/lodash@4.17.20
+  justification: because
+- vulnerability: CVE-2024-0003
+  component: pkg:npm/lodash@4.17.20
+  state: in_triage
+  response: later
+`,
+			wantErr: []string{
+				`entry 1 (CVE-2024-0001): invalid analysis state "ignored"`,
+				"entry 2 (): vulnerability is required",
+				`invalid analysis justification "because"`,
+				`entry 3 (CVE-2024-0003): invalid analysis response "later"`,
+			},
+		},
+		{
+			name:    "entry without state",
+			file:    "triage.yml",
+			content: "- vulnerability: CVE-2024-0001\n  component: pkg:npm/lodash@4.17.20\n  comment: reviewed\n",
+			wantErr: []string{"entry 1 (CVE-2024-0001): state is required"},
+		},
+		{
+			name:    "csv row without component",
+			file:    "triage.csv",
+			content: "vulnerability,component\nCVE-2024-0001,\n",
+			wantErr: []string{"entry 1 (CVE-2024-0001): component is required"},
+		},
+		{
+			name:    "invalid csv suppressed value",
+			file:    "triage.csv",
+			content: "vulnerability,component,suppressed\nCVE-2024-0001,pkg:npm/lodash,maybe\n",
+			wantErr: []string{`line 2: invalid suppressed value "maybe"`},
+		},
+		{
+			name:    "unsupported extension",
+			file:    "triage.json",
+			content: "[]",
+			wantErr: []string{"unsupported bulk file"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ParseBulkFile(path)
+			if len(tt.wantErr) > 0 {
+				if err == nil {
+					t.Fatalf("ParseBulkFile() error = nil, want %q", tt.wantErr)
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("ParseBulkFile() error = %q, want it to contain %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseBulkFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseBulkFile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package triage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+var (
+	states = []dtrack.AnalysisState{
+		dtrack.AnalysisStateExploitable,
+		dtrack.AnalysisStateFalsePositive,
+		dtrack.AnalysisStateInTriage,
+		dtrack.AnalysisStateNotAffected,
+		dtrack.AnalysisStateNotSet,
+		dtrack.AnalysisStateResolved,
+	}
+	justifications = []dtrack.AnalysisJustification{
+		dtrack.AnalysisJustificationCodeNotPresent,
+		dtrack.AnalysisJustificationCodeNotReachable,
+		dtrack.AnalysisJustificationNotSet,
+		dtrack.AnalysisJustificationProtectedAtPerimeter,
+		dtrack.AnalysisJustificationProtectedAtRuntime,
+		dtrack.AnalysisJustificationProtectedByCompiler,
+		dtrack.AnalysisJustificationProtectedByMitigatingControl,
+		dtrack.AnalysisJustificationRequiresConfiguration,
+		dtrack.AnalysisJustificationRequiresDependency,
+		dtrack.AnalysisJustificationRequiresEnvironment,
+	}
+	responses = []dtrack.AnalysisResponse{
+		dtrack.AnalysisResponseCanNotFix,
+		dtrack.AnalysisResponseNotSet,
+		dtrack.AnalysisResponseRollback,
+		dtrack.AnalysisResponseUpdate,
+		dtrack.AnalysisResponseWillNotFix,
+		dtrack.AnalysisResponseWorkaroundAvailable,
+	}
+)
+
+// ParseState converts a case-insensitive analysis state such as "not_affected" or "not-affected". An empty string
+// is returned as is, meaning "leave the state alone".
+func ParseState(s string) (dtrack.AnalysisState, error) {
+	return parseEnum("analysis state", s, states)
+}
+
+// ParseJustification converts a case-insensitive analysis justification such as "code_not_reachable".
+func ParseJustification(s string) (dtrack.AnalysisJustification, error) {
+	return parseEnum("analysis justification", s, justifications)
+}
+
+// ParseResponse converts a case-insensitive analysis response such as "will_not_fix".
+func ParseResponse(s string) (dtrack.AnalysisResponse, error) {
+	return parseEnum("analysis response", s, responses)
+}
+
+func parseEnum[T ~string](kind string, s string, values []T) (T, error) {
+	if s == "" {
+		return "", nil
+	}
+	v := T(strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), "-", "_")))
+	if !slices.Contains(values, v) {
+		return "", fmt.Errorf("invalid %s %q, expected one of %v", kind, s, values)
+	}
+	return v, nil
+}
@@ -0,0 +1,43 @@
+package triage
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+)
+
+// WriteResults prints one line per result followed by the count of each action. The rule of each decision is shown
+// when the decisions come from auto-triage rules.
+func WriteResults(w io.Writer, results []Result) error {
+	withRules := slices.ContainsFunc(results, func(r Result) bool { return r.Decision.Rule != "" })
+	counts := map[Action]int{}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	header := "ACTION\tVULNERABILITY\tCOMPONENT\tVERSION\tSTATE\tSUPPRESSED"
+	if withRules {
+		header += "\tRULE"
+	}
+	fmt.Fprintln(tw, header)
+	for _, r := range results {
+		counts[r.Action]++
+		state := string(r.Decision.State)
+		if state == "" {
+			state = "-"
+		}
+		component, version := "-", "-"
+		if r.Action != ActionNoMatch {
+			component, version = r.Finding.Component.Name, r.Finding.Component.Version
+		}
+		line := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%t", r.Action, r.Decision.VulnID, component, version, state, r.Decision.Suppressed)
+		if withRules {
+			line += "\t" + r.Decision.Rule
+		}
+		fmt.Fprintln(tw, line)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d applied, %d unsuppressed, %d kept, %d unchanged, %d without matching finding\n",
+		counts[ActionApplied], counts[ActionUnsuppressed], counts[ActionKept], counts[ActionUnchanged], counts[ActionNoMatch])
+	return err
+}
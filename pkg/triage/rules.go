@@ -0,0 +1,298 @@
+package triage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/weeros/trivy-plugin-dependencytrack/pkg/findings"
+
+	dtrack "github.com/DependencyTrack/client-go"
+	"go.yaml.in/yaml/v3"
+)
+
+// Rule records an analysis on every finding matching all of its conditions. Within a condition, any value matches.
+type Rule struct {
+	Name     string       `mapstructure:"name" yaml:"name"`
+	Match    RuleMatch    `mapstructure:"match" yaml:"match"`
+	Analysis RuleAnalysis `mapstructure:"analysis" yaml:"analysis"`
+}
+
+// RuleMatch holds the conditions of a rule. Empty conditions are not checked.
+type RuleMatch struct {
+	// PURLs are globs where "*" matches any characters. A glob without version matches every version.
+	PURLs []string `mapstructure:"purls" yaml:"purls"`
+	// Scopes are CycloneDX component scopes [required, optional, excluded], read from the uploaded sbom.
+	Scopes []string `mapstructure:"scopes" yaml:"scopes"`
+	// Vulnerabilities are globs matched against the vulnerability ID and its aliases, e.g. "CVE-2023-*".
+	Vulnerabilities []string `mapstructure:"vulnerabilities" yaml:"vulnerabilities"`
+	CWEs            []int    `mapstructure:"cwes" yaml:"cwes"`
+	Severities      []string `mapstructure:"severities" yaml:"severities"`
+}
+
+// RuleAnalysis is the analysis recorded by a rule.
+type RuleAnalysis struct {
+	State         string `mapstructure:"state" yaml:"state"`
+	Justification string `mapstructure:"justification" yaml:"justification"`
+	Response      string `mapstructure:"response" yaml:"response"`
+	Details       string `mapstructure:"details" yaml:"details"`
+	Suppress      bool   `mapstructure:"suppress" yaml:"suppress"`
+}
+
+var scopes = []string{"required", "optional", "excluded"}
+
+// ruleComment prefixes the comment of every analysis recorded by a rule, telling them from analyses made by hand.
+const ruleComment = "Auto-triaged by rule "
+
+// ParseRulesFile reads the rules listed under the "rules" key of a YAML file.
+func ParseRulesFile(path string) ([]Rule, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Rules []Rule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return doc.Rules, nil
+}
+
+// RuleSet is a validated list of rules, evaluated in order.
+type RuleSet struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	Rule
+	decision        Decision
+	purls           []*regexp.Regexp
+	vulnerabilities []*regexp.Regexp
+}
+
+// NewRuleSet validates the rules and reports every invalid one at once. A rule without any condition is rejected,
+// it would triage every finding of the project.
+func NewRuleSet(rules []Rule) (*RuleSet, error) {
+	var (
+		set  RuleSet
+		errs []error
+	)
+	for i, r := range rules {
+		c, err := compileRule(r)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %d (%s): %w", i+1, r.Name, err))
+			continue
+		}
+		set.rules = append(set.rules, c)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+// Len returns the number of rules.
+func (s *RuleSet) Len() int {
+	return len(s.rules)
+}
+
+func compileRule(r Rule) (compiledRule, error) {
+	var errs []error
+	if r.Name == "" {
+		errs = append(errs, fmt.Errorf("name is required"))
+	}
+	m := r.Match
+	if len(m.PURLs) == 0 && len(m.Scopes) == 0 && len(m.Vulnerabilities) == 0 && len(m.CWEs) == 0 && len(m.Severities) == 0 {
+		errs = append(errs, fmt.Errorf("at least one match condition is required"))
+	}
+	for _, s := range m.Scopes {
+		if !slices.Contains(scopes, strings.ToLower(s)) {
+			errs = append(errs, fmt.Errorf("invalid scope %q, expected one of %v", s, scopes))
+		}
+	}
+	for _, s := range m.Severities {
+		if _, err := findings.ParseSeverity(s); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if r.Analysis.State == "" {
+		errs = append(errs, fmt.Errorf("analysis state is required"))
+	}
+	state, err := ParseState(r.Analysis.State)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	justification, err := ParseJustification(r.Analysis.Justification)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	response, err := ParseResponse(r.Analysis.Response)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	purls, err := compilePURLGlobs(m.PURLs)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	vulnerabilities, err := compileGlobs(m.Vulnerabilities)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return compiledRule{}, err
+	}
+
+	decision := Decision{
+		State:         state,
+		Justification: justification,
+		Response:      response,
+		Details:       r.Analysis.Details,
+		Comment:       fmt.Sprintf("%s%q", ruleComment, r.Name),
+		Suppressed:    r.Analysis.Suppress,
+		Rule:          r.Name,
+		AnalyzedBy:    ruleComment,
+	}
+	return compiledRule{Rule: r, decision: decision, purls: purls, vulnerabilities: vulnerabilities}, nil
+}
+
+// Decisions returns one decision per finding matched by a rule, the first matching rule winning. componentScopes maps
+// component purls to their scope, see BOMScopes. An analysis recorded by hand is kept by Apply unless overwrite is set.
+func (s *RuleSet) Decisions(fs []dtrack.Finding, componentScopes map[string]string, overwrite bool) []Decision {
+	var decisions []Decision
+	for _, f := range fs {
+		for _, r := range s.rules {
+			if !r.matches(f, componentScopes) {
+				continue
+			}
+			d := r.decision
+			d.VulnID = f.Vulnerability.VulnID
+			d.ComponentUUID = f.Component.UUID
+			if overwrite {
+				d.AnalyzedBy = ""
+			}
+			decisions = append(decisions, d)
+			break
+		}
+	}
+	return decisions
+}
+
+func (r compiledRule) matches(f dtrack.Finding, componentScopes map[string]string) bool {
+	m := r.Match
+	if len(r.purls) > 0 && !slices.ContainsFunc(r.purls, func(g *regexp.Regexp) bool { return matchPURLGlob(g, f.Component.PURL) }) {
+		return false
+	}
+	if len(m.Scopes) > 0 {
+		scope, ok := componentScopes[trimPURL(f.Component.PURL)]
+		if !ok || !slices.ContainsFunc(m.Scopes, func(s string) bool { return strings.EqualFold(s, scope) }) {
+			return false
+		}
+	}
+	if len(r.vulnerabilities) > 0 && !slices.ContainsFunc(r.vulnerabilities, func(g *regexp.Regexp) bool { return matchVulnerabilityGlob(g, f.Vulnerability) }) {
+		return false
+	}
+	if len(m.CWEs) > 0 && !slices.ContainsFunc(f.Vulnerability.CWEs, func(c dtrack.CWE) bool { return slices.Contains(m.CWEs, c.ID) }) {
+		return false
+	}
+	if len(m.Severities) > 0 && !slices.ContainsFunc(m.Severities, func(s string) bool { return strings.EqualFold(s, f.Vulnerability.Severity) }) {
+		return false
+	}
+	return true
+}
+
+// compileGlobs turns globs into anchored, case-insensitive regular expressions where "*" matches any characters and
+// "?" one character.
+func compileGlobs(globs []string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+	for _, g := range globs {
+		if g == "" {
+			return nil, fmt.Errorf("empty glob")
+		}
+		expr := regexp.QuoteMeta(g)
+		expr = strings.ReplaceAll(expr, `\*`, ".*")
+		expr = strings.ReplaceAll(expr, `\?`, ".")
+		re, err := regexp.Compile("(?i)^" + expr + "$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", g, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// compilePURLGlobs compiles purl globs without their qualifiers and subpath, so that "?" starts the qualifiers rather
+// than matching one character.
+func compilePURLGlobs(globs []string) ([]*regexp.Regexp, error) {
+	trimmed := make([]string, len(globs))
+	for i, g := range globs {
+		trimmed[i] = trimPURL(g)
+	}
+	return compileGlobs(trimmed)
+}
+
+// matchPURLGlob matches the whole purl first, then the purl without version so that globs without version match
+// every version.
+func matchPURLGlob(g *regexp.Regexp, purl string) bool {
+	purl = trimPURL(purl)
+	if purl == "" {
+		return false
+	}
+	if g.MatchString(purl) {
+		return true
+	}
+	name, _ := splitPURLVersion(purl)
+	return g.MatchString(name)
+}
+
+func matchVulnerabilityGlob(g *regexp.Regexp, v dtrack.FindingVulnerability) bool {
+	if g.MatchString(v.VulnID) {
+		return true
+	}
+	for _, a := range v.Aliases {
+		for _, alias := range []string{a.CveID, a.GhsaID, a.GsdID, a.InternalID, a.OsvID, a.SonatypeId} {
+			if alias != "" && g.MatchString(alias) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type bomComponent struct {
+	PURL       string         `json:"purl"`
+	Scope      string         `json:"scope"`
+	Components []bomComponent `json:"components"`
+}
+
+// BOMScopes maps the purls of a CycloneDX JSON sbom, nested components included, to their scope. A component without
+// scope is required, as per the CycloneDX specification.
+func BOMScopes(content []byte) (map[string]string, error) {
+	var bom struct {
+		Components []bomComponent `json:"components"`
+	}
+	if err := json.Unmarshal(content, &bom); err != nil {
+		return nil, fmt.Errorf("failed to decode cyclonedx sbom: %w", err)
+	}
+
+	res := map[string]string{}
+	var walk func([]bomComponent)
+	walk = func(components []bomComponent) {
+		for _, c := range components {
+			if c.PURL != "" {
+				scope := strings.ToLower(c.Scope)
+				if scope == "" {
+					scope = "required"
+				}
+				res[trimPURL(c.PURL)] = scope
+			}
+			walk(c.Components)
+		}
+	}
+	walk(bom.Components)
+	return res, nil
+}
@@ -0,0 +1,334 @@
+package triage
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	dtrack "github.com/DependencyTrack/client-go"
+	"github.com/google/uuid"
+)
+
+func TestCompileRule(t *testing.T) {
+	valid := Rule{
+		Name:     "kernel",
+		Match:    RuleMatch{PURLs: []string{"pkg:deb/debian/linux-*"}},
+		Analysis: RuleAnalysis{State: "not_affected", Justification: "code_not_present", Response: "will_not_fix", Suppress: true},
+	}
+
+	tests := []struct {
+		name    string
+		rule    func(r *Rule)
+		wantErr []string
+	}{
+		{name: "valid rule", rule: func(r *Rule) {}},
+		{
+			name: "missing name and conditions",
+			rule: func(r *Rule) {
+				r.Name = ""
+				r.Match = RuleMatch{}
+			},
+			wantErr: []string{"name is required", "at least one match condition is required"},
+		},
+		{
+			name:    "missing state",
+			rule:    func(r *Rule) { r.Analysis.State = "" },
+			wantErr: []string{"analysis state is required"},
+		},
+		{
+			name: "invalid analysis",
+			rule: func(r *Rule) {
+				r.Analysis = RuleAnalysis{State: "ignored", Justification: "because", Response: "later"}
+			},
+			wantErr: []string{
+				`invalid analysis state "ignored"`,
+				`invalid analysis justification "because"`,
+				`invalid analysis response "later"`,
+			},
+		},
+		{
+			name:    "invalid scope and severity",
+			rule:    func(r *Rule) { r.Match = RuleMatch{Scopes: []string{"dev"}, Severities: []string{"urgent"}} },
+			wantErr: []string{`invalid scope "dev"`, "invalid severity: urgent"},
+		},
+		{
+			name:    "empty glob",
+			rule:    func(r *Rule) { r.Match.Vulnerabilities = []string{""} },
+			wantErr: []string{"empty glob"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid
+			tt.rule(&r)
+			c, err := compileRule(r)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("compileRule() error = %v", err)
+				}
+				d := c.decision
+				if d.State != dtrack.AnalysisStateNotAffected || d.Justification != dtrack.AnalysisJustificationCodeNotPresent ||
+					d.Response != dtrack.AnalysisResponseWillNotFix || !d.Suppressed || d.Rule != "kernel" ||
+					d.Comment != `Auto-triaged by rule "kernel"` || d.AnalyzedBy != ruleComment {
+					t.Errorf("compileRule() decision = %+v", d)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("compileRule() error = nil, want %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("compileRule() error = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRuleSetReportsEveryRule(t *testing.T) {
+	_, err := NewRuleSet([]Rule{
+		{Name: "first", Match: RuleMatch{CWEs: []int{79}}},
+		{Name: "second", Analysis: RuleAnalysis{State: "resolved"}},
+	})
+	if err == nil {
+		t.Fatal("NewRuleSet() error = nil")
+	}
+	for _, want := range []string{"rule 1 (first): analysis state is required", "rule 2 (second): at least one match condition is required"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("NewRuleSet() error = %q, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestCompileGlobs(t *testing.T) {
+	tests := []struct {
+		glob  string
+		value string
+		want  bool
+	}{
+		{glob: "CVE-2023-*", value: "CVE-2023-12345", want: true},
+		{glob: "CVE-2023-*", value: "cve-2023-1", want: true},
+		{glob: "CVE-2023-*", value: "CVE-2024-1", want: false},
+		{glob: "CVE-2023-000?", value: "CVE-2023-0001", want: true},
+		{glob: "CVE-2023-000?", value: "CVE-2023-00011", want: false},
+		{glob: "GHSA-*.x", value: "GHSA-a.x", want: true},
+		{glob: "GHSA-*.x", value: "GHSA-abx", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.glob+" "+tt.value, func(t *testing.T) {
+			res, err := compileGlobs([]string{tt.glob})
+			if err != nil {
+				t.Fatalf("compileGlobs() error = %v", err)
+			}
+			if got := res[0].MatchString(tt.value); got != tt.want {
+				t.Errorf("%s matches %q = %v, want %v", res[0], tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchPURLGlob(t *testing.T) {
+	tests := []struct {
+		glob string
+		purl string
+		want bool
+	}{
+		{glob: "pkg:deb/debian/linux-*", purl: "pkg:deb/debian/linux-libc-dev@6.1.0?arch=amd64", want: true},
+		{glob: "pkg:npm/lodash", purl: "pkg:npm/lodash@4.17.20", want: true},
+		{glob: "pkg:npm/lodash@4.*", purl: "pkg:npm/lodash@4.17.20", want: true},
+		{glob: "pkg:npm/lodash@4.*", purl: "pkg:npm/lodash@3.10.1", want: false},
+		{glob: "pkg:npm/@babel/*", purl: "pkg:npm/@babel/core@7.0.0", want: true},
+		{glob: "pkg:npm/lodash", purl: "pkg:npm/lodash-es@4.17.20", want: false},
+		{glob: "pkg:npm/lodash?arch=x64", purl: "pkg:npm/lodash@4.17.20?arch=arm64", want: true},
+		{glob: "*", purl: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.glob+" "+tt.purl, func(t *testing.T) {
+			res, err := compilePURLGlobs([]string{tt.glob})
+			if err != nil {
+				t.Fatalf("compilePURLGlobs() error = %v", err)
+			}
+			if got := matchPURLGlob(res[0], tt.purl); got != tt.want {
+				t.Errorf("matchPURLGlob(%q, %q) = %v, want %v", tt.glob, tt.purl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBOMScopes(t *testing.T) {
+	scopes, err := BOMScopes([]byte(`{"components": [
+		{"purl": "pkg:npm/express@4.18.0"},
+		{"purl": "pkg:npm/jest@29.0.0?foo=bar", "scope": "Optional", "components": [
+			{"purl": "pkg:npm/expect@29.0.0", "scope": "excluded"}
+		]},
+		{"name": "without purl"}
+	]}`))
+	if err != nil {
+		t.Fatalf("BOMScopes() error = %v", err)
+	}
+	want := map[string]string{
+		"pkg:npm/express@4.18.0": "required",
+		"pkg:npm/jest@29.0.0":    "optional",
+		"pkg:npm/expect@29.0.0":  "excluded",
+	}
+	if len(scopes) != len(want) {
+		t.Errorf("BOMScopes() = %v, want %v", scopes, want)
+	}
+	for purl, scope := range want {
+		if scopes[purl] != scope {
+			t.Errorf("BOMScopes()[%q] = %q, want %q", purl, scopes[purl], scope)
+		}
+	}
+
+	if _, err := BOMScopes([]byte(`<bom/>`)); err == nil {
+		t.Error("BOMScopes() of an xml sbom error = nil")
+	}
+}
+
+func TestRuleSetDecisions(t *testing.T) {
+	rules, err := NewRuleSet([]Rule{
+		{
+			Name:     "test-scope",
+			Match:    RuleMatch{Scopes: []string{"optional", "excluded"}},
+			Analysis: RuleAnalysis{State: "not_affected", Justification: "code_not_reachable"},
+		},
+		{
+			Name:     "old-xss",
+			Match:    RuleMatch{Vulnerabilities: []string{"CVE-2023-*"}, CWEs: []int{79}, Severities: []string{"low", "medium"}},
+			Analysis: RuleAnalysis{State: "false_positive"},
+		},
+		{
+			Name:     "npm",
+			Match:    RuleMatch{PURLs: []string{"pkg:npm/*"}},
+			Analysis: RuleAnalysis{State: "exploitable"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewRuleSet() error = %v", err)
+	}
+	scopes := map[string]string{"pkg:npm/jest@29.0.0": "optional", "pkg:npm/express@4.18.0": "required"}
+
+	finding := func(purl string, vulnID string, alias string, severity string, cwe int) dtrack.Finding {
+		f := dtrack.Finding{
+			Component:     dtrack.FindingComponent{UUID: uuid.New(), PURL: purl},
+			Vulnerability: dtrack.FindingVulnerability{VulnID: vulnID, Severity: severity, CWEs: []dtrack.CWE{{ID: cwe}}},
+		}
+		if alias != "" {
+			f.Vulnerability.Aliases = []dtrack.VulnerabilityAlias{{CveID: alias}}
+		}
+		return f
+	}
+	tests := []struct {
+		name    string
+		finding dtrack.Finding
+		want    string
+	}{
+		{name: "scope wins over the later rules", finding: finding("pkg:npm/jest@29.0.0", "CVE-2023-0001", "", "LOW", 79), want: "test-scope"},
+		{name: "every condition of the second rule", finding: finding("pkg:npm/express@4.18.0", "CVE-2023-0001", "", "MEDIUM", 79), want: "old-xss"},
+		{name: "vulnerability alias", finding: finding("pkg:deb/debian/curl@7.0", "GHSA-xxxx", "CVE-2023-0002", "LOW", 79), want: "old-xss"},
+		{name: "one condition failing", finding: finding("pkg:npm/express@4.18.0", "CVE-2023-0001", "", "HIGH", 79), want: "npm"},
+		{name: "component missing from the sbom", finding: finding("pkg:npm/mocha@10.0.0", "CVE-2024-0001", "", "LOW", 89), want: "npm"},
+		{name: "no rule", finding: finding("pkg:deb/debian/curl@7.0", "CVE-2024-0001", "", "LOW", 79), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decisions := rules.Decisions([]dtrack.Finding{tt.finding}, scopes, false)
+			if tt.want == "" {
+				if len(decisions) != 0 {
+					t.Errorf("Decisions() = %+v, want none", decisions)
+				}
+				return
+			}
+			if len(decisions) != 1 {
+				t.Fatalf("Decisions() = %+v, want one", decisions)
+			}
+			d := decisions[0]
+			if d.Rule != tt.want || d.VulnID != tt.finding.Vulnerability.VulnID || d.ComponentUUID != tt.finding.Component.UUID {
+				t.Errorf("Decisions() = %+v, want rule %s on the finding", d, tt.want)
+			}
+			if d.AnalyzedBy != ruleComment {
+				t.Errorf("Decisions() AnalyzedBy = %q, want %q", d.AnalyzedBy, ruleComment)
+			}
+		})
+	}
+
+	decisions := rules.Decisions([]dtrack.Finding{finding("pkg:npm/mocha@10.0.0", "CVE-2024-0001", "", "LOW", 89)}, scopes, true)
+	if len(decisions) != 1 || decisions[0].AnalyzedBy != "" {
+		t.Errorf("Decisions() with overwrite = %+v, want no AnalyzedBy", decisions)
+	}
+}
+
+func TestApplyRulesKeepsManualAnalyses(t *testing.T) {
+	const byRule = `Auto-triaged by rule "npm"`
+	tests := []struct {
+		name      string
+		state     dtrack.AnalysisState
+		comments  []string
+		overwrite bool
+		want      Action
+	}{
+		{name: "no analysis", want: ActionApplied},
+		{name: "not set", state: dtrack.AnalysisStateNotSet, want: ActionApplied},
+		{name: "in triage", state: dtrack.AnalysisStateInTriage, comments: []string{"Analysis: NOT_SET → IN_TRIAGE", "Looking into it"}, want: ActionApplied},
+		{
+			name:     "made by a rule",
+			state:    dtrack.AnalysisStateFalsePositive,
+			comments: []string{"Analysis: NOT_SET → FALSE_POSITIVE", `Auto-triaged by rule "old"`},
+			want:     ActionApplied,
+		},
+		{
+			name:     "made by hand",
+			state:    dtrack.AnalysisStateFalsePositive,
+			comments: []string{"Analysis: NOT_SET → FALSE_POSITIVE", "Reviewed with the security team"},
+			want:     ActionKept,
+		},
+		{
+			name:     "changed by hand after a rule",
+			state:    dtrack.AnalysisStateResolved,
+			comments: []string{"Analysis: NOT_SET → FALSE_POSITIVE", byRule, "Analysis: FALSE_POSITIVE → RESOLVED"},
+			want:     ActionKept,
+		},
+		{
+			name:      "made by hand with overwrite",
+			state:     dtrack.AnalysisStateFalsePositive,
+			comments:  []string{"Analysis: NOT_SET → FALSE_POSITIVE"},
+			overwrite: true,
+			want:      ActionApplied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := dtrack.Finding{
+				Component:     dtrack.FindingComponent{UUID: uuid.New(), Name: "lodash", PURL: "pkg:npm/lodash@4.17.20"},
+				Vulnerability: dtrack.FindingVulnerability{UUID: uuid.New(), VulnID: "CVE-2024-0001"},
+				Analysis:      dtrack.FindingAnalysis{State: string(tt.state)},
+			}
+			client, written := fakeAnalyses(t, map[uuid.UUID][]string{f.Vulnerability.UUID: tt.comments})
+
+			rules, err := NewRuleSet([]Rule{{Name: "npm", Match: RuleMatch{PURLs: []string{"pkg:npm/*"}}, Analysis: RuleAnalysis{State: "exploitable"}}})
+			if err != nil {
+				t.Fatalf("NewRuleSet() error = %v", err)
+			}
+			findings := []dtrack.Finding{f}
+			results, err := Apply(context.Background(), client, uuid.New(), findings, rules.Decisions(findings, nil, tt.overwrite), false)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if len(results) != 1 || results[0].Action != tt.want {
+				t.Fatalf("Apply() results = %+v, want one %s", results, tt.want)
+			}
+
+			requests := written()
+			if tt.want == ActionKept {
+				if len(requests) != 0 {
+					t.Errorf("wrote analyses %+v, want none", requests)
+				}
+				return
+			}
+			if len(requests) != 1 || requests[0].State != dtrack.AnalysisStateExploitable || requests[0].Comment != byRule {
+				t.Errorf("wrote analyses %+v, want the analysis of the rule", requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+// Package triage records analysis decisions on DependencyTrack findings.
+package triage
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	dtrack "github.com/DependencyTrack/client-go"
+	"github.com/google/uuid"
+)
+
+// Decision is an analysis to record on the findings it matches.
+type Decision struct {
+	// VulnID matches the finding vulnerability ID or one of its aliases.
+	VulnID string
+	// PURLs restricts the decision to components matching one of these purls. A purl without version matches every
+	// version of the package. An empty list matches every component.
+	PURLs []string
+	// ComponentUUID restricts the decision to one component.
+	ComponentUUID uuid.UUID
+
+	State         dtrack.AnalysisState
+	Justification dtrack.AnalysisJustification
+	Response      dtrack.AnalysisResponse
+	Details       string
+	Comment       string
+	Suppressed    bool
+	// Force records the analysis even when the finding already carries the decided state and suppression, e.g. to
+	// update the justification or details of an explicit decision.
+	Force bool
+	// Rule names the auto-triage rule the decision comes from, if any.
+	Rule string
+	// SuppressedBy, on a decision lifting a suppression, is the comment prefix of the decisions allowed to have made
+	// it. A suppression recorded since by someone else is kept.
+	SuppressedBy string
+	// AnalyzedBy, on a decision recording a state, is the comment prefix of the decisions allowed to have made the
+	// current analysis. An analysis other than NOT_SET and IN_TRIAGE recorded since by someone else is kept.
+	AnalyzedBy string
+}
+
+// Action tells what Apply did for a finding.
+type Action string
+
+const (
+	ActionApplied      Action = "applied"
+	ActionUnsuppressed Action = "unsuppressed"
+	ActionUnchanged    Action = "unchanged"
+	ActionNoMatch      Action = "no-match"
+	// ActionKept is a suppression or an analysis left in place because it was not made by the decisions of
+	// SuppressedBy or AnalyzedBy.
+	ActionKept Action = "kept"
+)
+
+// Result is the outcome of a decision on one finding. Finding is empty for ActionNoMatch.
+type Result struct {
+	Action   Action
+	Decision Decision
+	Finding  dtrack.Finding
+}
+
+// FetchFindings returns every finding of a project, suppressed ones included.
+func FetchFindings(ctx context.Context, client *dtrack.Client, projectUUID uuid.UUID) ([]dtrack.Finding, error) {
+	return dtrack.FetchAll(func(po dtrack.PageOptions) (dtrack.Page[dtrack.Finding], error) {
+		return client.Finding.GetAll(ctx, projectUUID, true, po)
+	})
+}
+
+// Matches reports whether the decision applies to the finding.
+func (d Decision) Matches(f dtrack.Finding) bool {
+	if !matchesVulnerability(d.VulnID, f.Vulnerability) {
+		return false
+	}
+	if d.ComponentUUID != uuid.Nil && d.ComponentUUID != f.Component.UUID {
+		return false
+	}
+	if len(d.PURLs) == 0 {
+		return true
+	}
+	for _, p := range d.PURLs {
+		if MatchPURL(p, f.Component.PURL) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply records each decision on the matching findings of a project. Findings already carrying the decided state and
+// suppression are left untouched so that reruns do not pile up comments. With dryRun, nothing is written.
+func Apply(ctx context.Context, client *dtrack.Client, projectUUID uuid.UUID, findings []dtrack.Finding, decisions []Decision, dryRun bool) ([]Result, error) {
+	var results []Result
+	for _, d := range decisions {
+		matched := false
+		for _, f := range findings {
+			if !d.Matches(f) {
+				continue
+			}
+			matched = true
+
+			action := ActionApplied
+			if d.State == "" {
+				// Only the suppression changes, e.g. when an ignore entry expired.
+				if f.Analysis.Suppressed == d.Suppressed {
+					results = append(results, Result{Action: ActionUnchanged, Decision: d, Finding: f})
+					continue
+				}
+				action = ActionUnsuppressed
+				if d.Suppressed {
+					action = ActionApplied
+				} else if d.SuppressedBy != "" {
+					owned, err := changedBy(ctx, client, projectUUID, f, d.SuppressedBy, isSuppressionComment)
+					if err != nil {
+						return results, err
+					}
+					if !owned {
+						results = append(results, Result{Action: ActionKept, Decision: d, Finding: f})
+						continue
+					}
+				}
+			} else if !d.Force && f.Analysis.State == string(d.State) && f.Analysis.Suppressed == d.Suppressed {
+				results = append(results, Result{Action: ActionUnchanged, Decision: d, Finding: f})
+				continue
+			} else if d.AnalyzedBy != "" && analyzed(f) {
+				owned, err := changedBy(ctx, client, projectUUID, f, d.AnalyzedBy, isStateComment)
+				if err != nil {
+					return results, err
+				}
+				if !owned {
+					results = append(results, Result{Action: ActionKept, Decision: d, Finding: f})
+					continue
+				}
+			}
+
+			if !dryRun {
+				state := d.State
+				if state == "" {
+					state = dtrack.AnalysisState(f.Analysis.State)
+				}
+				_, err := client.Analysis.Create(ctx, dtrack.AnalysisRequest{
+					Component:     f.Component.UUID,
+					Project:       projectUUID,
+					Vulnerability: f.Vulnerability.UUID,
+					Comment:       d.Comment,
+					State:         state,
+					Justification: d.Justification,
+					Response:      d.Response,
+					Details:       d.Details,
+					Suppressed:    dtrack.OptionalBoolOf(d.Suppressed),
+				})
+				if err != nil {
+					return results, fmt.Errorf("failed to record analysis of %s on %s: %w", f.Vulnerability.VulnID, f.Component.Name, err)
+				}
+			}
+			results = append(results, Result{Action: action, Decision: d, Finding: f})
+		}
+		if !matched {
+			results = append(results, Result{Action: ActionNoMatch, Decision: d})
+		}
+	}
+	return results, nil
+}
+
+// analyzed reports whether a finding carries a decided analysis, i.e. a state other than NOT_SET and IN_TRIAGE.
+func analyzed(f dtrack.Finding) bool {
+	switch dtrack.AnalysisState(f.Analysis.State) {
+	case "", dtrack.AnalysisStateNotSet, dtrack.AnalysisStateInTriage:
+		return false
+	}
+	return true
+}
+
+// isSuppressionComment matches the comment DependencyTrack logs when a request suppresses an existing analysis.
+func isSuppressionComment(comment string) bool {
+	return comment == "Suppressed"
+}
+
+// isStateComment matches the comment DependencyTrack logs when a request changes the state of an analysis, e.g.
+// "Analysis: NOT_SET → NOT_AFFECTED".
+func isStateComment(comment string) bool {
+	return strings.HasPrefix(comment, "Analysis: ")
+}
+
+// changedBy reports whether the last change of a finding analysis, as logged by the comments matching isChange, was
+// recorded along with a comment starting with prefix. DependencyTrack logs the change comments of a request before
+// the comment of the request itself. A new analysis only gets the latter for its suppression.
+func changedBy(ctx context.Context, client *dtrack.Client, projectUUID uuid.UUID, f dtrack.Finding, prefix string, isChange func(string) bool) (bool, error) {
+	analysis, err := client.Analysis.Get(ctx, f.Component.UUID, projectUUID, f.Vulnerability.UUID)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch analysis of %s on %s: %w", f.Vulnerability.VulnID, f.Component.Name, err)
+	}
+	comments := slices.Clone(analysis.Comments)
+	slices.SortStableFunc(comments, func(a, b dtrack.AnalysisComment) int { return cmp.Compare(a.Timestamp, b.Timestamp) })
+	changed, by := -1, -1
+	for i, c := range comments {
+		switch {
+		case isChange(c.Comment):
+			changed = i
+		case strings.HasPrefix(c.Comment, prefix):
+			by = i
+		}
+	}
+	return by > changed, nil
+}
+
+// MatchPURL reports whether a component purl matches a pattern purl. Qualifiers and subpaths are ignored, and a
+// pattern without version matches every version.
+func MatchPURL(pattern string, purl string) bool {
+	pattern = trimPURL(pattern)
+	purl = trimPURL(purl)
+	if pattern == "" || purl == "" {
+		return false
+	}
+	if _, version := splitPURLVersion(pattern); version != "" {
+		return pattern == purl
+	}
+	name, _ := splitPURLVersion(purl)
+	return pattern == name
+}
+
+func trimPURL(p string) string {
+	p, _, _ = strings.Cut(p, "#")
+	p, _, _ = strings.Cut(p, "?")
+	return p
+}
+
+// splitPURLVersion separates the version of a purl. Only an "@" after the last "/" starts the version, so that
+// unencoded npm scopes such as pkg:npm/@babel/core are kept in the name.
+func splitPURLVersion(p string) (string, string) {
+	i := strings.LastIndex(p, "@")
+	if i < 0 || i < strings.LastIndex(p, "/") {
+		return p, ""
+	}
+	return p[:i], p[i+1:]
+}
+
+func matchesVulnerability(id string, v dtrack.FindingVulnerability) bool {
+	if strings.EqualFold(id, v.VulnID) {
+		return true
+	}
+	for _, a := range v.Aliases {
+		for _, alias := range []string{a.CveID, a.GhsaID, a.GsdID, a.InternalID, a.OsvID, a.SonatypeId} {
+			if alias != "" && strings.EqualFold(id, alias) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package triage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	dtrack "github.com/DependencyTrack/client-go"
+	"go.yaml.in/yaml/v3"
+)
+
+// IgnoreEntry is one vulnerability ignored by a .trivyignore or .trivyignore.yaml file.
+type IgnoreEntry struct {
+	ID        string
+	PURLs     []string
+	Paths     []string
+	Statement string
+	// ExpiredAt is the zero time when the entry never expires.
+	ExpiredAt time.Time
+}
+
+// Expired reports whether the entry expired at the given time.
+func (e IgnoreEntry) Expired(now time.Time) bool {
+	return !e.ExpiredAt.IsZero() && now.After(e.ExpiredAt)
+}
+
+// DefaultIgnoreFiles are looked up, in order, when no ignore file is given.
+var DefaultIgnoreFiles = []string{".trivyignore.yaml", ".trivyignore"}
+
+// FindIgnoreFile returns the first default ignore file present in dir.
+func FindIgnoreFile(dir string) (string, error) {
+	for _, name := range DefaultIgnoreFiles {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("none of %v found in %s", DefaultIgnoreFiles, dir)
+}
+
+// ParseIgnoreFile reads the vulnerability entries of a trivy ignore file. Files ending in .yaml or .yml use the
+// structured format, any other file the plain one.
+func ParseIgnoreFile(path string) ([]IgnoreEntry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		return ParseIgnoreYAML(content)
+	default:
+		return ParseIgnore(content)
+	}
+}
+
+// ParseIgnore reads the plain .trivyignore format: one ID per line with an optional "exp:YYYY-MM-DD" suffix, and
+// "#" comments. The comment lines right above an entry become its statement.
+func ParseIgnore(content []byte) ([]IgnoreEntry, error) {
+	var (
+		entries  []IgnoreEntry
+		comments []string
+		lineNo   int
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			comments = nil
+			continue
+		case strings.HasPrefix(line, "#"):
+			comments = append(comments, strings.TrimSpace(strings.TrimPrefix(line, "#")))
+			continue
+		}
+
+		fields := strings.Fields(line)
+		entry := IgnoreEntry{ID: fields[0], Statement: strings.Join(comments, " ")}
+		comments = nil
+		for _, field := range fields[1:] {
+			if strings.HasPrefix(field, "#") {
+				break
+			}
+			exp, ok := strings.CutPrefix(field, "exp:")
+			if !ok {
+				continue
+			}
+			t, err := time.Parse(time.DateOnly, exp)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid expiration date %q: %w", lineNo, exp, err)
+			}
+			entry.ExpiredAt = t
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+type ignoreYAML struct {
+	Vulnerabilities []struct {
+		ID        string   `yaml:"id"`
+		Paths     []string `yaml:"paths"`
+		PURLs     []string `yaml:"purls"`
+		Statement string   `yaml:"statement"`
+		ExpiredAt string   `yaml:"expired_at"`
+	} `yaml:"vulnerabilities"`
+}
+
+// ParseIgnoreYAML reads the vulnerabilities section of the .trivyignore.yaml format. Misconfigurations, secrets and
+// licenses are not findings in DependencyTrack and are ignored.
+func ParseIgnoreYAML(content []byte) ([]IgnoreEntry, error) {
+	var doc ignoreYAML
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode trivyignore yaml: %w", err)
+	}
+
+	entries := make([]IgnoreEntry, 0, len(doc.Vulnerabilities))
+	for i, v := range doc.Vulnerabilities {
+		if v.ID == "" {
+			return nil, fmt.Errorf("vulnerabilities[%d]: id is required", i)
+		}
+		entry := IgnoreEntry{ID: v.ID, PURLs: v.PURLs, Paths: v.Paths, Statement: v.Statement}
+		if v.ExpiredAt != "" {
+			t, err := time.Parse(time.DateOnly, v.ExpiredAt)
+			if err != nil {
+				return nil, fmt.Errorf("vulnerabilities[%d] (%s): invalid expired_at %q: %w", i, v.ID, v.ExpiredAt, err)
+			}
+			entry.ExpiredAt = t
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// IgnoreDecisions maps ignore entries to decisions. Active entries record the given state and justification and
+// suppress the findings; expired entries only lift the suppressions recorded by an import of the same file, so that
+// one made by someone else in DependencyTrack is kept. Entries restricted by paths alone cannot be
+// mapped, DependencyTrack does not know where a component was found, and are returned apart.
+func IgnoreDecisions(entries []IgnoreEntry, source string, state dtrack.AnalysisState, justification dtrack.AnalysisJustification, now time.Time) ([]Decision, []IgnoreEntry) {
+	var (
+		decisions []Decision
+		unmapped  []IgnoreEntry
+	)
+	for _, e := range entries {
+		if len(e.Paths) > 0 && len(e.PURLs) == 0 {
+			unmapped = append(unmapped, e)
+			continue
+		}
+
+		if e.Expired(now) {
+			decisions = append(decisions, Decision{
+				VulnID:       e.ID,
+				PURLs:        e.PURLs,
+				Comment:      fmt.Sprintf("%s entry expired on %s", source, e.ExpiredAt.Format(time.DateOnly)),
+				Suppressed:   false,
+				SuppressedBy: ignoreComment(source),
+			})
+			continue
+		}
+
+		comment := ignoreComment(source)
+		if e.Statement != "" {
+			comment += ": " + e.Statement
+		}
+		if !e.ExpiredAt.IsZero() {
+			comment += fmt.Sprintf(" (expires %s)", e.ExpiredAt.Format(time.DateOnly))
+		}
+		decisions = append(decisions, Decision{
+			VulnID:        e.ID,
+			PURLs:         e.PURLs,
+			State:         state,
+			Justification: justification,
+			Details:       e.Statement,
+			Comment:       comment,
+			Suppressed:    true,
+		})
+	}
+	return decisions, unmapped
+}
+
+// ignoreComment starts the comment of the analyses recorded from an ignore file.
+func ignoreComment(source string) string {
+	return "Imported from " + source
+}
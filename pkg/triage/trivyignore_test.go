@@ -0,0 +1,131 @@
+package triage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	dtrack "github.com/DependencyTrack/client-go"
+	"github.com/google/uuid"
+)
+
+// fakeAnalyses serves the analysis of each finding, with the given comments, and records the analyses written.
+func fakeAnalyses(t *testing.T, comments map[uuid.UUID][]string) (*dtrack.Client, func() []dtrack.AnalysisRequest) {
+	var mu sync.Mutex
+	var written []dtrack.AnalysisRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/version", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(dtrack.About{Version: "4.11.0"})
+	})
+	mux.HandleFunc("GET /api/v1/analysis", func(w http.ResponseWriter, r *http.Request) {
+		var analysis dtrack.Analysis
+		for i, c := range comments[uuid.MustParse(r.URL.Query().Get("vulnerability"))] {
+			analysis.Comments = append(analysis.Comments, dtrack.AnalysisComment{Comment: c, Commenter: "someone", Timestamp: 1000 + i})
+		}
+		_ = json.NewEncoder(w).Encode(analysis)
+	})
+	mux.HandleFunc("PUT /api/v1/analysis", func(w http.ResponseWriter, r *http.Request) {
+		var req dtrack.AnalysisRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		written = append(written, req)
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(dtrack.Analysis{State: req.State})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := dtrack.NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, func() []dtrack.AnalysisRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(written)
+	}
+}
+
+func TestApplyExpiredIgnoreEntries(t *testing.T) {
+	const imported = "Imported from .trivyignore.yaml: not reachable"
+	tests := []struct {
+		name       string
+		suppressed bool
+		comments   []string
+		want       Action
+	}{
+		{
+			name:       "suppression recorded by the import",
+			suppressed: true,
+			comments:   []string{imported},
+			want:       ActionUnsuppressed,
+		},
+		{
+			name:       "suppression recorded again by a later import",
+			suppressed: true,
+			comments:   []string{imported, "Unsuppressed", ".trivyignore.yaml entry expired on 2024-01-01", "Suppressed", imported},
+			want:       ActionUnsuppressed,
+		},
+		{
+			name:       "suppression recorded by someone else after the import",
+			suppressed: true,
+			comments:   []string{imported, "Unsuppressed", "Suppressed", "False positive, see ticket"},
+			want:       ActionKept,
+		},
+		{
+			name:       "suppression never recorded by the import",
+			suppressed: true,
+			comments:   []string{"Analysis: NOT_SET → FALSE_POSITIVE", "Suppressed"},
+			want:       ActionKept,
+		},
+		{
+			name:       "finding not suppressed",
+			suppressed: false,
+			comments:   []string{imported, "Suppressed", "Unsuppressed"},
+			want:       ActionUnchanged,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := dtrack.Finding{
+				Component:     dtrack.FindingComponent{UUID: uuid.New(), Name: "openssl", PURL: "pkg:deb/debian/openssl@3.0.11"},
+				Vulnerability: dtrack.FindingVulnerability{UUID: uuid.New(), VulnID: "CVE-2024-0001"},
+				Analysis:      dtrack.FindingAnalysis{State: string(dtrack.AnalysisStateNotAffected), Suppressed: tt.suppressed},
+			}
+			client, written := fakeAnalyses(t, map[uuid.UUID][]string{f.Vulnerability.UUID: tt.comments})
+
+			entries := []IgnoreEntry{{ID: "CVE-2024-0001", ExpiredAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}}
+			decisions, _ := IgnoreDecisions(entries, ".trivyignore.yaml", dtrack.AnalysisStateNotAffected, dtrack.AnalysisJustificationCodeNotReachable, time.Now())
+			results, err := Apply(context.Background(), client, uuid.New(), []dtrack.Finding{f}, decisions, false)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if len(results) != 1 || results[0].Action != tt.want {
+				t.Fatalf("Apply() results = %+v, want one %s", results, tt.want)
+			}
+
+			requests := written()
+			if tt.want != ActionUnsuppressed {
+				if len(requests) != 0 {
+					t.Errorf("wrote analyses %+v, want none", requests)
+				}
+				return
+			}
+			if len(requests) != 1 {
+				t.Fatalf("wrote %d analyses, want 1", len(requests))
+			}
+			req := requests[0]
+			if req.Suppressed == nil || *req.Suppressed || req.State != dtrack.AnalysisStateNotAffected {
+				t.Errorf("wrote state %s suppressed %v, want the state kept and the suppression lifted", req.State, req.Suppressed)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Package vex reads, validates and writes the VEX documents exchanged with DependencyTrack.
+package vex
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// CycloneDX is the subset of a CycloneDX BOM used to carry VEX statements.
+type CycloneDX struct {
+	BOMFormat       string          `json:"bomFormat"`
+	SpecVersion     string          `json:"specVersion"`
+	SerialNumber    string          `json:"serialNumber,omitempty"`
+	Version         int             `json:"version"`
+	Metadata        *Metadata       `json:"metadata,omitempty"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+}
+
+// Metadata describes when and for what the VEX document was produced.
+type Metadata struct {
+	Timestamp string     `json:"timestamp,omitempty"`
+	Component *Component `json:"component,omitempty"`
+}
+
+// Component identifies the product the VEX document is about.
+type Component struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	BOMRef  string `json:"bom-ref,omitempty"`
+}
+
+// Vulnerability is one VEX statement.
+type Vulnerability struct {
+	BOMRef   string    `json:"bom-ref,omitempty"`
+	ID       string    `json:"id"`
+	Source   *Source   `json:"source,omitempty"`
+	Analysis *Analysis `json:"analysis,omitempty"`
+	Affects  []Affect  `json:"affects"`
+}
+
+// Source is the database a vulnerability ID comes from.
+type Source struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// Analysis is the impact analysis of a vulnerability.
+type Analysis struct {
+	State         string   `json:"state,omitempty"`
+	Justification string   `json:"justification,omitempty"`
+	Response      []string `json:"response,omitempty"`
+	Detail        string   `json:"detail,omitempty"`
+}
+
+// Affect references a component affected by the vulnerability, by bom-ref or BOM-Link.
+type Affect struct {
+	Ref string `json:"ref"`
+}
+
+var (
+	specVersions   = []string{"1.4", "1.5", "1.6"}
+	states         = []string{"resolved", "resolved_with_pedigree", "exploitable", "in_triage", "false_positive", "not_affected"}
+	justifications = []string{"code_not_present", "code_not_reachable", "requires_configuration", "requires_dependency",
+		"requires_environment", "protected_by_compiler", "protected_at_runtime", "protected_at_perimeter",
+		"protected_by_mitigating_control"}
+	responses = []string{"can_not_fix", "will_not_fix", "update", "rollback", "workaround_available"}
+)
+
+// ParseCycloneDX decodes and validates a CycloneDX VEX JSON document. Every problem found is reported at once.
+func ParseCycloneDX(data []byte) (CycloneDX, error) {
+	var doc CycloneDX
+	if !json.Valid(data) {
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+			return doc, fmt.Errorf("only CycloneDX JSON VEX documents are supported")
+		}
+		return doc, fmt.Errorf("vex document is not valid JSON")
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return doc, fmt.Errorf("failed to decode vex document: %w", err)
+	}
+	return doc, doc.Validate()
+}
+
+// Validate checks that the document is a CycloneDX VEX that DependencyTrack can import.
+func (d CycloneDX) Validate() error {
+	var errs []error
+	if d.BOMFormat != "CycloneDX" {
+		errs = append(errs, fmt.Errorf("bomFormat must be CycloneDX, got %q", d.BOMFormat))
+	}
+	if !slices.Contains(specVersions, d.SpecVersion) {
+		errs = append(errs, fmt.Errorf("unsupported specVersion %q, expected one of %v", d.SpecVersion, specVersions))
+	}
+	if len(d.Vulnerabilities) == 0 {
+		errs = append(errs, fmt.Errorf("document contains no vulnerabilities"))
+	}
+
+	for i, v := range d.Vulnerabilities {
+		where := fmt.Sprintf("vulnerabilities[%d]", i)
+		if v.ID == "" {
+			errs = append(errs, fmt.Errorf("%s: id is required", where))
+		} else {
+			where = fmt.Sprintf("%s (%s)", where, v.ID)
+		}
+		if v.Analysis == nil {
+			errs = append(errs, fmt.Errorf("%s: analysis is required", where))
+		} else {
+			if !slices.Contains(states, v.Analysis.State) {
+				errs = append(errs, fmt.Errorf("%s: invalid analysis state %q", where, v.Analysis.State))
+			}
+			if v.Analysis.Justification != "" && !slices.Contains(justifications, v.Analysis.Justification) {
+				errs = append(errs, fmt.Errorf("%s: invalid analysis justification %q", where, v.Analysis.Justification))
+			}
+			for _, r := range v.Analysis.Response {
+				if !slices.Contains(responses, r) {
+					errs = append(errs, fmt.Errorf("%s: invalid analysis response %q", where, r))
+				}
+			}
+		}
+		if len(v.Affects) == 0 {
+			errs = append(errs, fmt.Errorf("%s: affects is required", where))
+		}
+		for j, a := range v.Affects {
+			if a.Ref == "" {
+				errs = append(errs, fmt.Errorf("%s: affects[%d].ref is required", where, j))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
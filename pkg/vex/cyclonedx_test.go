@@ -0,0 +1,106 @@
+package vex
+
+import (
+	"strings"
+	"testing"
+)
+
+const validVEX = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "version": 1,
+  "vulnerabilities": [
+    {
+      "id": "CVE-2024-0001",
+      "analysis": {"state": "not_affected", "justification": "code_not_reachable", "response": ["will_not_fix"]},
+      "affects": [{"ref": "urn:cdx:3e671687-395b-41f5-a30f-a58921a69b79/1#pkg:npm/lodash@4.17.20"}]
+    },
+    {
+      "id": "GHSA-xxxx-yyyy-zzzz",
+      "analysis": {"state": "in_triage"},
+      "affects": [{"ref": "pkg:npm/express@4.18.0"}]
+    }
+  ]
+}`
+
+func TestParseCycloneDX(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr []string
+	}{
+		{name: "valid document", data: validVEX},
+		{
+			name:    "xml document",
+			data:    `<?xml version="1.0"?><bom xmlns="http://cyclonedx.org/schema/bom/1.5"/>`,
+			wantErr: []string{"only CycloneDX JSON VEX documents are supported"},
+		},
+		{name: "not json", data: `{"bomFormat":`, wantErr: []string{"vex document is not valid JSON"}},
+		{
+			name:    "wrong json type",
+			data:    `{"bomFormat": "CycloneDX", "specVersion": 1.5}`,
+			wantErr: []string{"failed to decode vex document"},
+		},
+		{
+			name: "wrong bomFormat and specVersion",
+			data: strings.Replace(strings.Replace(validVEX, `"CycloneDX"`, `"SPDX"`, 1), `"1.5"`, `"1.2"`, 1),
+			wantErr: []string{
+				`bomFormat must be CycloneDX, got "SPDX"`,
+				`unsupported specVersion "1.2", expected one of [1.4 1.5 1.6]`,
+			},
+		},
+		{
+			name:    "missing vulnerabilities",
+			data:    `{"bomFormat": "CycloneDX", "specVersion": "1.6", "version": 1}`,
+			wantErr: []string{"document contains no vulnerabilities"},
+		},
+		{
+			name: "invalid analysis",
+			data: `{"bomFormat": "CycloneDX", "specVersion": "1.4", "vulnerabilities": [
+				{"id": "CVE-2024-0001", "analysis": {"state": "ignored", "justification": "because", "response": ["later"]},
+				 "affects": [{"ref": "pkg:npm/lodash@4.17.20"}]}
+			]}`,
+			wantErr: []string{
+				`vulnerabilities[0] (CVE-2024-0001): invalid analysis state "ignored"`,
+				`vulnerabilities[0] (CVE-2024-0001): invalid analysis justification "because"`,
+				`vulnerabilities[0] (CVE-2024-0001): invalid analysis response "later"`,
+			},
+		},
+		{
+			name: "every incomplete statement reported",
+			data: `{"bomFormat": "CycloneDX", "specVersion": "1.4", "vulnerabilities": [
+				{"analysis": {"state": "exploitable"}, "affects": [{"ref": "pkg:npm/lodash@4.17.20"}]},
+				{"id": "CVE-2024-0002", "affects": [{"ref": ""}]},
+				{"id": "CVE-2024-0003", "analysis": {"state": "resolved"}}
+			]}`,
+			wantErr: []string{
+				"vulnerabilities[0]: id is required",
+				"vulnerabilities[1] (CVE-2024-0002): analysis is required",
+				"vulnerabilities[1] (CVE-2024-0002): affects[0].ref is required",
+				"vulnerabilities[2] (CVE-2024-0003): affects is required",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseCycloneDX([]byte(tt.data))
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("ParseCycloneDX() error = %v", err)
+				}
+				if len(doc.Vulnerabilities) != 2 || doc.Vulnerabilities[0].Analysis.Justification != "code_not_reachable" {
+					t.Errorf("ParseCycloneDX() = %+v", doc)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("ParseCycloneDX() error = nil, want %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("ParseCycloneDX() error = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package vex
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	dtrack "github.com/DependencyTrack/client-go"
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+)
+
+// Decision is a DependencyTrack analysis of one vulnerability on one component.
+type Decision struct {
+	VulnID           string
+	Source           string
+	PURL             string
+	ComponentName    string
+	ComponentVersion string
+	State            dtrack.AnalysisState
+	Justification    dtrack.AnalysisJustification
+	Response         dtrack.AnalysisResponse
+	Details          string
+}
+
+// analysisConcurrency bounds the analyses fetched at once: the findings only carry the analysis state, so the
+// justification, response and details take one request per analysed finding.
+const analysisConcurrency = 8
+
+// FetchDecisions returns the analysis decisions recorded on the findings of a project, suppressed ones included.
+// Findings that were never analysed are left out.
+func FetchDecisions(ctx context.Context, client *dtrack.Client, projectUUID uuid.UUID) ([]Decision, error) {
+	findings, err := dtrack.FetchAll(func(po dtrack.PageOptions) (dtrack.Page[dtrack.Finding], error) {
+		return client.Finding.GetAll(ctx, projectUUID, true, po)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch findings: %w", err)
+	}
+
+	var analysed []dtrack.Finding
+	for _, f := range findings {
+		if f.Analysis.State != "" && f.Analysis.State != string(dtrack.AnalysisStateNotSet) {
+			analysed = append(analysed, f)
+		}
+	}
+
+	decisions := make([]Decision, len(analysed))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(analysisConcurrency)
+	for i, f := range analysed {
+		g.Go(func() error {
+			analysis, err := client.Analysis.Get(gctx, f.Component.UUID, projectUUID, f.Vulnerability.UUID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch analysis of %s on %s: %w", f.Vulnerability.VulnID, f.Component.Name, err)
+			}
+			decisions[i] = Decision{
+				VulnID:           f.Vulnerability.VulnID,
+				Source:           f.Vulnerability.Source,
+				PURL:             f.Component.PURL,
+				ComponentName:    f.Component.Name,
+				ComponentVersion: f.Component.Version,
+				State:            analysis.State,
+				Justification:    analysis.Justification,
+				Response:         analysis.Response,
+				Details:          analysis.Details,
+			}
+			return nil
+		})
+	}
+	err = g.Wait()
+	if err != nil {
+		return nil, err
+	}
+	return decisions, nil
+}
+
+// ToCycloneDX converts decisions to a CycloneDX VEX document. When bomSerial is set, affected components are
+// referenced with BOM-Links into that BOM, otherwise with their purl, which trivy does not apply to a scanned BOM.
+// Decisions without a purl are skipped and returned so that the caller can report them.
+func ToCycloneDX(product Component, bomSerial string, bomVersion int, decisions []Decision) (CycloneDX, []Decision) {
+	doc := CycloneDX{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + uuid.NewString(),
+		Version:      1,
+		Metadata: &Metadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Component: &product,
+		},
+		Vulnerabilities: []Vulnerability{},
+	}
+
+	var skipped []Decision
+	for _, d := range decisions {
+		if d.PURL == "" {
+			skipped = append(skipped, d)
+			continue
+		}
+		ref := d.PURL
+		if bomSerial != "" {
+			ref = fmt.Sprintf("urn:cdx:%s/%d#%s", strings.TrimPrefix(bomSerial, "urn:uuid:"), bomVersion, d.PURL)
+		}
+
+		analysis := &Analysis{
+			State:  strings.ToLower(string(d.State)),
+			Detail: d.Details,
+		}
+		if d.Justification != "" && d.Justification != dtrack.AnalysisJustificationNotSet {
+			analysis.Justification = strings.ToLower(string(d.Justification))
+		}
+		if d.Response != "" && d.Response != dtrack.AnalysisResponseNotSet {
+			analysis.Response = []string{strings.ToLower(string(d.Response))}
+		}
+
+		var source *Source
+		if d.Source != "" {
+			source = &Source{Name: d.Source}
+		}
+		doc.Vulnerabilities = append(doc.Vulnerabilities, Vulnerability{
+			ID:       d.VulnID,
+			Source:   source,
+			Analysis: analysis,
+			Affects:  []Affect{{Ref: ref}},
+		})
+	}
+	return doc, skipped
+}
@@ -0,0 +1,206 @@
+package vex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	dtrack "github.com/DependencyTrack/client-go"
+	"github.com/google/uuid"
+)
+
+// fakeFindings serves the findings of a project, every even one being analysed, and the analysis of each of them.
+func fakeFindings(t *testing.T, count int, failVuln string) (*dtrack.Client, *atomic.Int32) {
+	var findings []dtrack.Finding
+	vulnIDs := map[uuid.UUID]string{}
+	for i := range count {
+		f := dtrack.Finding{
+			Component:     dtrack.FindingComponent{UUID: uuid.New(), Name: fmt.Sprintf("component-%d", i), PURL: fmt.Sprintf("pkg:npm/component-%d@1.0.0", i)},
+			Vulnerability: dtrack.FindingVulnerability{UUID: uuid.New(), VulnID: fmt.Sprintf("CVE-2024-%04d", i), Source: "NVD"},
+		}
+		if i%2 == 0 {
+			f.Analysis.State = string(dtrack.AnalysisStateNotAffected)
+		}
+		findings = append(findings, f)
+		vulnIDs[f.Vulnerability.UUID] = f.Vulnerability.VulnID
+	}
+
+	var mu sync.Mutex
+	var inFlight int32
+	maxInFlight := &atomic.Int32{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/version", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(dtrack.About{Version: "4.11.0"})
+	})
+	mux.HandleFunc("GET /api/v1/finding/project/{uuid}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Total-Count", strconv.Itoa(len(findings)))
+		_ = json.NewEncoder(w).Encode(findings)
+	})
+	mux.HandleFunc("GET /api/v1/analysis", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight.Load() {
+			maxInFlight.Store(inFlight)
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		vulnID := vulnIDs[uuid.MustParse(r.URL.Query().Get("vulnerability"))]
+		if vulnID == failVuln {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(dtrack.Analysis{
+			State:         dtrack.AnalysisStateNotAffected,
+			Justification: dtrack.AnalysisJustificationCodeNotReachable,
+			Details:       "reviewed " + vulnID,
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := dtrack.NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, maxInFlight
+}
+
+func TestFetchDecisions(t *testing.T) {
+	client, maxInFlight := fakeFindings(t, 60, "")
+
+	decisions, err := FetchDecisions(context.Background(), client, uuid.New())
+	if err != nil {
+		t.Fatalf("FetchDecisions() error = %v", err)
+	}
+	if len(decisions) != 30 {
+		t.Fatalf("got %d decisions, want the 30 analysed findings", len(decisions))
+	}
+	for i, d := range decisions {
+		wantID := fmt.Sprintf("CVE-2024-%04d", 2*i)
+		if d.VulnID != wantID || d.Details != "reviewed "+wantID {
+			t.Errorf("decision %d = %s %q, want %s in the findings order", i, d.VulnID, d.Details, wantID)
+		}
+		if d.Justification != dtrack.AnalysisJustificationCodeNotReachable {
+			t.Errorf("decision %d justification = %s", i, d.Justification)
+		}
+	}
+	if got := maxInFlight.Load(); got > analysisConcurrency || got < 2 {
+		t.Errorf("fetched up to %d analyses at once, want between 2 and %d", got, analysisConcurrency)
+	}
+}
+
+func TestFetchDecisionsError(t *testing.T) {
+	client, _ := fakeFindings(t, 20, "CVE-2024-0010")
+
+	_, err := FetchDecisions(context.Background(), client, uuid.New())
+	if err == nil {
+		t.Fatal("FetchDecisions() error = nil, want the failed analysis")
+	}
+}
+
+func TestToCycloneDX(t *testing.T) {
+	decisions := []Decision{
+		{
+			VulnID: "CVE-2024-0001", Source: "NVD", PURL: "pkg:npm/lodash@4.17.20", State: dtrack.AnalysisStateNotAffected,
+			Justification: dtrack.AnalysisJustificationCodeNotReachable, Response: dtrack.AnalysisResponseWillNotFix, Details: "only in tests",
+		},
+		{
+			VulnID: "GHSA-xxxx-yyyy-zzzz", PURL: "pkg:npm/express@4.18.0", State: dtrack.AnalysisStateInTriage,
+			Justification: dtrack.AnalysisJustificationNotSet, Response: dtrack.AnalysisResponseNotSet,
+		},
+		{VulnID: "CVE-2024-0003", ComponentName: "vendored", State: dtrack.AnalysisStateFalsePositive},
+	}
+
+	tests := []struct {
+		name      string
+		bomSerial string
+		wantRefs  []string
+	}{
+		{
+			name:     "purl references without bom",
+			wantRefs: []string{"pkg:npm/lodash@4.17.20", "pkg:npm/express@4.18.0"},
+		},
+		{
+			name:      "bom-link references",
+			bomSerial: "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+			wantRefs: []string{
+				"urn:cdx:3e671687-395b-41f5-a30f-a58921a69b79/2#pkg:npm/lodash@4.17.20",
+				"urn:cdx:3e671687-395b-41f5-a30f-a58921a69b79/2#pkg:npm/express@4.18.0",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, skipped := ToCycloneDX(Component{Type: "application", Name: "app", Version: "1.0.0"}, tt.bomSerial, 2, decisions)
+			if len(skipped) != 1 || skipped[0].VulnID != "CVE-2024-0003" {
+				t.Errorf("skipped = %+v, want the decision without purl", skipped)
+			}
+			if len(doc.Vulnerabilities) != len(tt.wantRefs) {
+				t.Fatalf("vulnerabilities = %+v, want %d", doc.Vulnerabilities, len(tt.wantRefs))
+			}
+			for i, v := range doc.Vulnerabilities {
+				if len(v.Affects) != 1 || v.Affects[0].Ref != tt.wantRefs[i] {
+					t.Errorf("vulnerabilities[%d].affects = %+v, want %s", i, v.Affects, tt.wantRefs[i])
+				}
+			}
+
+			first, second := doc.Vulnerabilities[0], doc.Vulnerabilities[1]
+			if first.Source == nil || first.Source.Name != "NVD" || second.Source != nil {
+				t.Errorf("sources = %+v, %+v, want NVD and none", first.Source, second.Source)
+			}
+			wantFirst := Analysis{State: "not_affected", Justification: "code_not_reachable", Response: []string{"will_not_fix"}, Detail: "only in tests"}
+			if fmt.Sprint(*first.Analysis) != fmt.Sprint(wantFirst) {
+				t.Errorf("analysis = %+v, want %+v", *first.Analysis, wantFirst)
+			}
+			if second.Analysis.Justification != "" || second.Analysis.Response != nil {
+				t.Errorf("analysis = %+v, want NOT_SET justification and response left out", *second.Analysis)
+			}
+			if err := doc.Validate(); err != nil {
+				t.Errorf("exported document is invalid: %v", err)
+			}
+		})
+	}
+}
+
+func TestToOpenVEX(t *testing.T) {
+	decisions := []Decision{
+		{VulnID: "CVE-2024-0001", PURL: "pkg:npm/a@1", State: dtrack.AnalysisStateNotAffected, Justification: dtrack.AnalysisJustificationCodeNotReachable},
+		{VulnID: "CVE-2024-0002", PURL: "pkg:npm/b@1", State: dtrack.AnalysisStateFalsePositive},
+		{VulnID: "CVE-2024-0003", PURL: "pkg:npm/c@1", State: dtrack.AnalysisStateExploitable, Response: dtrack.AnalysisResponseUpdate, Details: "bump to 2.0"},
+		{VulnID: "CVE-2024-0004", PURL: "pkg:npm/d@1", State: dtrack.AnalysisStateResolved, Details: "patched"},
+		{VulnID: "CVE-2024-0005", PURL: "pkg:npm/e@1", State: dtrack.AnalysisStateInTriage},
+		{VulnID: "CVE-2024-0006", ComponentName: "vendored", State: dtrack.AnalysisStateNotAffected},
+	}
+	want := []Statement{
+		{Vulnerability: StatementVulnerability{Name: "CVE-2024-0001"}, Products: []Product{{ID: "pkg:npm/a@1"}}, Status: "not_affected", Justification: "vulnerable_code_not_in_execute_path"},
+		{Vulnerability: StatementVulnerability{Name: "CVE-2024-0002"}, Products: []Product{{ID: "pkg:npm/b@1"}}, Status: "not_affected", Justification: "vulnerable_code_not_present"},
+		{Vulnerability: StatementVulnerability{Name: "CVE-2024-0003"}, Products: []Product{{ID: "pkg:npm/c@1"}}, Status: "affected", ActionStatement: "UPDATE: bump to 2.0"},
+		{Vulnerability: StatementVulnerability{Name: "CVE-2024-0004"}, Products: []Product{{ID: "pkg:npm/d@1"}}, Status: "fixed", StatusNotes: "patched"},
+		{Vulnerability: StatementVulnerability{Name: "CVE-2024-0005"}, Products: []Product{{ID: "pkg:npm/e@1"}}, Status: "under_investigation"},
+	}
+
+	doc, skipped := ToOpenVEX("security@example.com", decisions)
+	if len(skipped) != 1 || skipped[0].VulnID != "CVE-2024-0006" {
+		t.Errorf("skipped = %+v, want the decision without purl", skipped)
+	}
+	if fmt.Sprint(doc.Statements) != fmt.Sprint(want) {
+		t.Errorf("statements =\n%+v\nwant\n%+v", doc.Statements, want)
+	}
+	again, _ := ToOpenVEX("security@example.com", decisions)
+	if doc.ID == "" || again.ID != doc.ID {
+		t.Errorf("document ids %q and %q, want a stable id", doc.ID, again.ID)
+	}
+}
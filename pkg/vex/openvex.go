@@ -0,0 +1,127 @@
+package vex
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	dtrack "github.com/DependencyTrack/client-go"
+)
+
+// OpenVEXContext is the OpenVEX specification version written by ToOpenVEX.
+const OpenVEXContext = "https://openvex.dev/ns/v0.2.0"
+
+// OpenVEX is an OpenVEX document.
+type OpenVEX struct {
+	Context    string      `json:"@context"`
+	ID         string      `json:"@id"`
+	Author     string      `json:"author"`
+	Timestamp  string      `json:"timestamp"`
+	Version    int         `json:"version"`
+	Tooling    string      `json:"tooling,omitempty"`
+	Statements []Statement `json:"statements"`
+}
+
+// Statement is an OpenVEX statement about one vulnerability.
+type Statement struct {
+	Vulnerability   StatementVulnerability `json:"vulnerability"`
+	Products        []Product              `json:"products"`
+	Status          string                 `json:"status"`
+	Justification   string                 `json:"justification,omitempty"`
+	ImpactStatement string                 `json:"impact_statement,omitempty"`
+	ActionStatement string                 `json:"action_statement,omitempty"`
+	StatusNotes     string                 `json:"status_notes,omitempty"`
+}
+
+// StatementVulnerability names the vulnerability of a statement.
+type StatementVulnerability struct {
+	Name string `json:"name"`
+}
+
+// Product is a software identified by its purl.
+type Product struct {
+	ID string `json:"@id"`
+}
+
+// openVEXJustifications maps DependencyTrack justifications to the closest OpenVEX ones.
+var openVEXJustifications = map[dtrack.AnalysisJustification]string{
+	dtrack.AnalysisJustificationCodeNotPresent:               "vulnerable_code_not_present",
+	dtrack.AnalysisJustificationCodeNotReachable:             "vulnerable_code_not_in_execute_path",
+	dtrack.AnalysisJustificationRequiresConfiguration:        "vulnerable_code_cannot_be_controlled_by_adversary",
+	dtrack.AnalysisJustificationRequiresDependency:           "vulnerable_code_cannot_be_controlled_by_adversary",
+	dtrack.AnalysisJustificationRequiresEnvironment:          "vulnerable_code_cannot_be_controlled_by_adversary",
+	dtrack.AnalysisJustificationProtectedByCompiler:          "inline_mitigations_already_exist",
+	dtrack.AnalysisJustificationProtectedAtRuntime:           "inline_mitigations_already_exist",
+	dtrack.AnalysisJustificationProtectedAtPerimeter:         "inline_mitigations_already_exist",
+	dtrack.AnalysisJustificationProtectedByMitigatingControl: "inline_mitigations_already_exist",
+}
+
+// ToOpenVEX converts decisions to an OpenVEX document. Decisions without a purl are skipped and returned so that the
+// caller can report them.
+func ToOpenVEX(author string, decisions []Decision) (OpenVEX, []Decision) {
+	doc := OpenVEX{
+		Context:    OpenVEXContext,
+		Author:     author,
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Version:    1,
+		Tooling:    "trivy-plugin-dependencytrack",
+		Statements: []Statement{},
+	}
+
+	var skipped []Decision
+	for _, d := range decisions {
+		if d.PURL == "" {
+			skipped = append(skipped, d)
+			continue
+		}
+		s := Statement{
+			Vulnerability: StatementVulnerability{Name: d.VulnID},
+			Products:      []Product{{ID: d.PURL}},
+		}
+		switch d.State {
+		case dtrack.AnalysisStateNotAffected, dtrack.AnalysisStateFalsePositive:
+			s.Status = "not_affected"
+			s.Justification = openVEXJustifications[d.Justification]
+			if d.State == dtrack.AnalysisStateFalsePositive && s.Justification == "" {
+				s.Justification = "vulnerable_code_not_present"
+			}
+			s.ImpactStatement = d.Details
+			if s.Justification == "" && s.ImpactStatement == "" {
+				// OpenVEX requires one of them for not_affected statements.
+				s.ImpactStatement = "Analysed as " + string(d.State) + " in Dependency-Track"
+			}
+		case dtrack.AnalysisStateExploitable:
+			s.Status = "affected"
+			s.ActionStatement = actionStatement(d)
+		case dtrack.AnalysisStateResolved:
+			s.Status = "fixed"
+			s.StatusNotes = d.Details
+		default:
+			s.Status = "under_investigation"
+			s.StatusNotes = d.Details
+		}
+		doc.Statements = append(doc.Statements, s)
+	}
+
+	// The document ID must be stable for the same content, so derive it from the statements.
+	content, _ := json.Marshal(doc.Statements)
+	sum := sha256.Sum256(content)
+	doc.ID = "https://openvex.dev/docs/public/vex-" + hex.EncodeToString(sum[:])
+	return doc, skipped
+}
+
+func actionStatement(d Decision) string {
+	statement := d.Details
+	if d.Response != "" && d.Response != dtrack.AnalysisResponseNotSet {
+		if statement != "" {
+			statement = string(d.Response) + ": " + statement
+		} else {
+			statement = string(d.Response)
+		}
+	}
+	if statement == "" {
+		statement = "No action recorded in Dependency-Track"
+	}
+	return statement
+}